@@ -7,6 +7,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/CurtisVermeeren/advent-of-code-2022/day10/ocr"
 )
 
 func partOne() (int, error) {
@@ -60,12 +62,12 @@ func partOne() (int, error) {
 	return signalStrength, err
 }
 
-func partTwo() error {
+func partTwo() (string, error) {
 
 	file, err := os.Open("./input.txt")
 	defer file.Close()
 	if err != nil {
-		return err
+		return "", err
 	}
 	fileScanner := bufio.NewScanner(file)
 
@@ -75,40 +77,38 @@ func partTwo() error {
 	registerX := 1
 	currentCycle := 0
 
-	for fileScanner.Scan() {
-		instructions := strings.Fields(fileScanner.Text())
-
-		// Every 40 "pixels" of the screen is a new line
-		if (currentCycle)%40 == 0 {
-			fmt.Println()
-		}
+	// The crt screen is 40 x 6. Each pixel is drawn into the screen buffer so the letters can be read once drawing is done
+	screen := make([][]byte, 6)
+	for i := range screen {
+		screen[i] = []byte(strings.Repeat(".", 40))
+	}
 
+	// draw lights the pixel for the current cycle if the sprite is over it
+	draw := func() {
 		// registerX sets the horizontal position of the middle of the sprite "###"
-		// The crt screens draws a single pixel each cycle. The screen is 40 x 6. Cycle 1 to 40 draws the top row, Cycles 41 to 80 draw the second row and so on
+		// The crt screens draws a single pixel each cycle. Cycle 1 to 40 draws the top row, Cycles 41 to 80 draw the second row and so on
 		// If the sprite is position such that one of its three pixels is the pixel currently being drawn, a lit pixel is drawn "#"
-		if registerX-1 == currentCycle%40 || registerX == currentCycle%40 || registerX+1 == currentCycle%40 {
-			fmt.Print("#")
-		} else {
-			fmt.Print(".")
+		row, column := currentCycle/40, currentCycle%40
+		if row >= len(screen) {
+			return
+		}
+		if registerX-1 == column || registerX == column || registerX+1 == column {
+			screen[row][column] = '#'
 		}
+	}
 
+	for fileScanner.Scan() {
+		instructions := strings.Fields(fileScanner.Text())
+
+		draw()
 		currentCycle++
 
 		// If the instreuction is addx it takes an extra cycle
 		// increment the currentCycle again and do drawing necessary depending on registerX
 		if instructions[0] == "addx" {
 
-			// Every 40 "pixels" of the screen is a new line
-			if (currentCycle)%40 == 0 {
-				fmt.Println()
-			}
-
 			// Draw again to account for the second addx cycle
-			if registerX-1 == currentCycle%40 || registerX == currentCycle%40 || registerX+1 == currentCycle%40 {
-				fmt.Print("#")
-			} else {
-				fmt.Print(".")
-			}
+			draw()
 
 			// increment again. addx takes two cycles
 			currentCycle++
@@ -116,14 +116,22 @@ func partTwo() error {
 			// Add the value to the registerX
 			value, err := strconv.Atoi(instructions[1])
 			if err != nil {
-				return err
+				return "", err
 			}
 			registerX += value
 
 		}
 	}
 
-	return err
+	// Print the screen so it can still be checked by eye
+	rows := make([]string, len(screen))
+	for i, row := range screen {
+		rows[i] = string(row)
+		fmt.Println(rows[i])
+	}
+
+	// Read the capital letters drawn on the screen
+	return ocr.Decode(rows)
 }
 
 func main() {
@@ -133,5 +141,9 @@ func main() {
 	}
 	fmt.Println(signal)
 
-	partTwo()
+	letters, err := partTwo()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(letters)
 }
@@ -0,0 +1,84 @@
+package ocr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The Advent of Code font draws each capital letter in a box 4 pixels wide and 6 pixels tall
+// Letters are placed side by side with a single blank column between them so each letter takes up 5 columns
+const (
+	GlyphWidth   = 4
+	GlyphHeight  = 6
+	GlyphSpacing = GlyphWidth + 1
+)
+
+// Lit is the rune used for a lit pixel. Any other rune is treated as a dark pixel
+const Lit = '#'
+
+// font maps the rows of each known glyph joined together to the letter it represents
+// The rows are joined with no separator so each key is GlyphWidth * GlyphHeight runes long
+var font = map[string]rune{
+	".##." + "#..#" + "#..#" + "####" + "#..#" + "#..#": 'A',
+	"###." + "#..#" + "###." + "#..#" + "#..#" + "###.": 'B',
+	".##." + "#..#" + "#..." + "#..." + "#..#" + ".##.": 'C',
+	"####" + "#..." + "###." + "#..." + "#..." + "####": 'E',
+	"####" + "#..." + "###." + "#..." + "#..." + "#...": 'F',
+	".##." + "#..#" + "#..." + "#.##" + "#..#" + ".###": 'G',
+	"#..#" + "#..#" + "####" + "#..#" + "#..#" + "#..#": 'H',
+	".###" + "..#." + "..#." + "..#." + "..#." + ".###": 'I',
+	"..##" + "...#" + "...#" + "...#" + "#..#" + ".##.": 'J',
+	"#..#" + "#.#." + "##.." + "#.#." + "#.#." + "#..#": 'K',
+	"#..." + "#..." + "#..." + "#..." + "#..." + "####": 'L',
+	".##." + "#..#" + "#..#" + "#..#" + "#..#" + ".##.": 'O',
+	"###." + "#..#" + "#..#" + "###." + "#..." + "#...": 'P',
+	"###." + "#..#" + "#..#" + "###." + "#.#." + "#..#": 'R',
+	".###" + "#..." + "#..." + ".##." + "...#" + "###.": 'S',
+	"#..#" + "#..#" + "#..#" + "#..#" + "#..#" + ".##.": 'U',
+	"####" + "...#" + "..#." + ".#.." + "#..." + "####": 'Z',
+}
+
+// Decode reads the letters drawn on screen and returns them as a string
+// screen holds one string per row of pixels and must be exactly GlyphHeight rows tall
+// Every row must be the same width. The final letter does not need a trailing blank column
+// An error is returned if the screen is the wrong shape or a glyph does not match any known letter
+func Decode(screen []string) (string, error) {
+	if len(screen) != GlyphHeight {
+		return "", fmt.Errorf("screen has %d rows, expected %d", len(screen), GlyphHeight)
+	}
+
+	width := len(screen[0])
+	for y, row := range screen {
+		if len(row) != width {
+			return "", fmt.Errorf("row %d has width %d, expected %d", y, len(row), width)
+		}
+	}
+
+	var letters strings.Builder
+	for x := 0; x < width; x += GlyphSpacing {
+		if x+GlyphWidth > width {
+			return "", fmt.Errorf("partial glyph at column %d", x)
+		}
+
+		// Build the key for this glyph by joining each of its rows
+		// Normalise the pixels so that any dark rune is matched by the font table
+		var key strings.Builder
+		for y := 0; y < GlyphHeight; y++ {
+			for _, pixel := range screen[y][x : x+GlyphWidth] {
+				if pixel == Lit {
+					key.WriteRune('#')
+				} else {
+					key.WriteRune('.')
+				}
+			}
+		}
+
+		letter, ok := font[key.String()]
+		if !ok {
+			return "", fmt.Errorf("unrecognised glyph at column %d", x)
+		}
+		letters.WriteRune(letter)
+	}
+
+	return letters.String(), nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+)
+
+// itemState is everything needed to know how an item moves at the start of a round
+// Items never affect each other so an item's trajectory only depends on the monkey holding it and its worry level
+type itemState struct {
+	monkey int
+	worry  int
+}
+
+// simulateCycles returns how many items each monkey inspects over the given number of rounds without relief
+// Instead of simulating every round each item is followed on its own with its worry kept modulo the least common multiple of the divisors
+// There are only so many states an item can be in so its trajectory must eventually repeat
+// Once the state at the start of a round has been seen before the inspections for the remaining rounds are calculated from the cycle
+func simulateCycles(monkeys []Monkey, rounds int) ([]int, error) {
+	modulus := 1
+	for _, m := range monkeys {
+		modulus = lcm(modulus, m.divisor)
+	}
+	a := modArithmetic{modulus: modulus}
+	c := checkedArithmetic{}
+
+	// track how many items each monkey has inspected
+	inspectedItems := make([]int, len(monkeys))
+
+	for monkeyId, m := range monkeys {
+		for _, item := range m.items {
+			worry, err := a.fromInt(item)
+			if err != nil {
+				return nil, err
+			}
+
+			counts, err := traceItem(monkeys, itemState{monkey: monkeyId, worry: worry}, rounds, a)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, count := range counts {
+				if inspectedItems[i], err = c.apply('+', inspectedItems[i], count); err != nil {
+					return nil, fmt.Errorf("inspections of monkey %d: %w", i, err)
+				}
+			}
+		}
+	}
+
+	return inspectedItems, nil
+}
+
+// maxTracedStates is the most states traceItem remembers while looking for a cycle in an item's trajectory
+const maxTracedStates = 1 << 20
+
+// traceItem follows a single item from the state start and returns how many times each monkey inspects it over the given number of rounds
+func traceItem(monkeys []Monkey, start itemState, rounds int, a modArithmetic) ([]int, error) {
+	n := len(monkeys)
+
+	// seen maps the state at the start of a round to that round
+	// prefix holds the inspections by each monkey before each round. The counts before round r are prefix[r*n : r*n+n]
+	// counts holds the inspections by each monkey so far
+	seen := make(map[itemState]int)
+	prefix := make([]int, n)
+	counts := make([]int, n)
+
+	state := start
+	for round := 0; round < rounds; round++ {
+		if seen != nil {
+			if first, ok := seen[state]; ok {
+				return extrapolate(prefix, n, first, round, rounds)
+			}
+
+			// An item can be in up to modulus * monkeys states so a cycle may take too many rounds to remember
+			// Puzzle inputs repeat within a few thousand rounds. Past the limit stop looking and simulate every round instead
+			if len(seen) == maxTracedStates {
+				seen, prefix = nil, nil
+			} else {
+				seen[state] = round
+			}
+		}
+
+		// The item keeps moving in the same round while it is thrown to a monkey that hasn't had its turn yet
+		for {
+			m := monkeys[state.monkey]
+			counts[state.monkey]++
+
+			worry, err := evaluate[int](m.operation, state.worry, a)
+			if err != nil {
+				return nil, fmt.Errorf("round %d monkey %d: %w", round+1, state.monkey, err)
+			}
+
+			throwTo := m.ifFalse
+			if a.divisible(worry, m.divisor) {
+				throwTo = m.ifTrue
+			}
+
+			previous := state.monkey
+			state = itemState{monkey: throwTo, worry: worry}
+			if throwTo < previous {
+				break
+			}
+		}
+
+		if seen != nil {
+			prefix = append(prefix, counts...)
+		}
+	}
+
+	// The rounds ran out before the item repeated a state
+	return counts, nil
+}
+
+// extrapolate calculates the inspections after rounds from the prefix counts of an item whose state at round repeats its state at round first
+// Every period of round-first rounds adds the same inspections so only the whole cycles and the remainder need to be added
+func extrapolate(prefix []int, n, first, round, rounds int) ([]int, error) {
+	period := round - first
+	cycles := (rounds - first) / period
+	remainder := (rounds - first) % period
+
+	c := checkedArithmetic{}
+	counts := make([]int, n)
+	for i := range counts {
+		before := prefix[first*n+i]
+		perCycle := prefix[round*n+i] - before
+		partial := prefix[(first+remainder)*n+i] - before
+
+		total, err := c.apply('*', cycles, perCycle)
+		if err != nil {
+			return nil, err
+		}
+		if total, err = c.apply('+', total, before+partial); err != nil {
+			return nil, err
+		}
+		counts[i] = total
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// expr is a node in the expression tree of a monkey's Operation
+// The tree is evaluated with evaluate so that any worry backend can be used
+type expr interface {
+	String() string
+}
+
+// oldExpr is the "old" worry level of the item being inspected
+type oldExpr struct{}
+
+// constExpr is a number written in the Operation
+type constExpr int
+
+// binaryExpr applies the operator op to the result of the left and right expressions
+// op is one of '+', '-', '*' or '/'
+type binaryExpr struct {
+	op          byte
+	left, right expr
+}
+
+func (oldExpr) String() string {
+	return "old"
+}
+
+func (c constExpr) String() string {
+	return strconv.Itoa(int(c))
+}
+
+func (b binaryExpr) String() string {
+	return fmt.Sprintf("(%v %c %v)", b.left, b.op, b.right)
+}
+
+// evaluate calculates the new worry level of an item given its old worry level
+// The arithmetic a is used for every number and operator in the expression
+func evaluate[T any](e expr, old T, a arithmetic[T]) (T, error) {
+	switch e := e.(type) {
+	case oldExpr:
+		return old, nil
+	case constExpr:
+		return a.fromInt(int(e))
+	case binaryExpr:
+		left, err := evaluate(e.left, old, a)
+		if err != nil {
+			return left, err
+		}
+		right, err := evaluate(e.right, old, a)
+		if err != nil {
+			return right, err
+		}
+		return a.apply(e.op, left, right)
+	}
+
+	var zero T
+	return zero, fmt.Errorf("unknown expression %v", e)
+}
+
+// parseExpr parses the right hand side of an Operation such as "old * 19" or "3 - old"
+// Expressions can use "old", non-negative integers, parentheses and the operators + - * /
+// Multiplication and division bind tighter than addition and subtraction and all operators are left associative
+func parseExpr(s string) (expr, error) {
+	p := exprParser{input: s}
+	e, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole input must be used by the expression
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d in %q", p.input[p.pos], p.pos, s)
+	}
+
+	return e, nil
+}
+
+// exprParser is a recursive descent parser over the text of an expression
+// pos is the index of the next byte to read from input
+type exprParser struct {
+	input string
+	pos   int
+}
+
+// skipSpace moves pos past any whitespace
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non space byte without consuming it or 0 at the end of the input
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// parseSum parses terms joined by + or -
+func (p *exprParser) parseSum() (expr, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseProduct parses operands joined by * or /
+func (p *exprParser) parseProduct() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseOperand parses "old", a number or an expression in parentheses
+func (p *exprParser) parseOperand() (expr, error) {
+	c := p.peek()
+	start := p.pos
+
+	switch {
+	case c == '(':
+		p.pos++
+		e, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ')' at position %d in %q", p.pos, p.input)
+		}
+		p.pos++
+		return e, nil
+
+	case c >= '0' && c <= '9':
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		value, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil {
+			return nil, err
+		}
+		return constExpr(value), nil
+
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.input) && unicode.IsLetter(rune(p.input[p.pos])) {
+			p.pos++
+		}
+		if word := p.input[start:p.pos]; word != "old" {
+			return nil, fmt.Errorf("unknown variable %q in %q", word, p.input)
+		}
+		return oldExpr{}, nil
+
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression %q", p.input)
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d in %q", c, p.pos, p.input)
+}
@@ -1,235 +1,172 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
-	"strconv"
-	"strings"
 )
 
-type Monkey struct {
-	items        []int         // The items the monkey holds
-	operation    func(int) int // This function defines how the worry level changes as the monkey inspects an item
-	testAndThrow func(int) int // This functions tests which monkey the item will be thrown to
-}
-
-func partOne() (int, error) {
-
-	file, err := os.Open("./input.txt")
-	defer file.Close()
-	if err != nil {
-		return -1, err
-	}
-	fileScanner := bufio.NewScanner(file)
-
-	// monkeys holds all monkeys from the input file
-	var monkeys []Monkey
-
-	// Read the input and parse each monkey's information
-	for fileScanner.Scan() {
-
-		var newMonkey Monkey
-
-		// Read the starting items
-		fileScanner.Scan()
-		// Split the "Starting items: 76, 88, 96, 97, 58, 61, 67" strings by comma without the " Starting items: " text
-		// Then parse each worry value and add it to the monkey's items
-		// all items are in the order they will be inspected by the monkey
-		for _, item := range strings.Split(fileScanner.Text()[len("  Starting items: "):], ", ") {
-			worry, err := strconv.Atoi(item)
-			if err != nil {
-				return -1, err
-			}
-			newMonkey.items = append(newMonkey.items, worry)
-		}
+// worryFlag selects the worry backend for part two
+// Part one always uses checked as relief divides worry by three and only exact values can be divided
+var worryFlag = flag.String("worry", string(modMode), "worry backend for part two: big, mod or checked")
 
-		// Read the Operation:
-		fileScanner.Scan()
-		var operator rune
-		var value int = 0
-		// Check if the operation for the monkey inpuit is "old * old" or an "old * value"
-		if fileScanner.Text() == "  Operation: new = old * old" {
-			operator = '*'
-		} else {
-			fmt.Sscanf(fileScanner.Text(), " Operation: new = old %c %d", &operator, &value)
-		}
-		// Create the Operation function for the monkey Operation input
-		newMonkey.operation = createOperationOne(operator, value)
+// engineFlag selects how part two is solved
+// "rounds" simulates every round while "cycles" follows each item until its trajectory repeats
+var engineFlag = flag.String("engine", "rounds", "engine for part two: rounds or cycles")
 
-		// Read the Test:
-		fileScanner.Scan()
-		var testValue int
-		// Read the test case
-		fmt.Sscanf(fileScanner.Text(), "  Test: divisible by %d", &testValue)
+// roundsFlag is the number of rounds for part two
+var roundsFlag = flag.Int("rounds", 10000, "number of rounds for part two")
 
-		// Read the true expression to find the monkey the item is thrown to if the test case is true
-		fileScanner.Scan()
-		var monkeyThrownIfTrue int
-		fmt.Sscanf(fileScanner.Text(), "    If true: throw to monkey %d", &monkeyThrownIfTrue)
+func partOne() (*big.Int, error) {
 
-		// Read the false expression to find the monkey the item is thrown to if the test case is false
-		fileScanner.Scan()
-		var monkeyThrownIfFalse int
-		fmt.Sscanf(fileScanner.Text(), "    If false: throw to monkey %d", &monkeyThrownIfFalse)
+	monkeys, err := parseInput()
+	if err != nil {
+		return nil, err
+	}
 
-		// Create a function that runs the test case
-		newMonkey.testAndThrow = createTest(testValue, monkeyThrownIfTrue, monkeyThrownIfFalse)
+	// Worry is divided by three in "relief" after each inspection so values must be exact
+	// Values stay small over 20 rounds so a checked int is enough
+	// Problem asks for answer after 20 rounds of monkey buisness
+	inspectedItems, err := simulateMode(monkeys, 20, 3, checkedMode)
+	if err != nil {
+		return nil, err
+	}
 
-		// Add the monkey to the slice of all monkeys
-		monkeys = append(monkeys, newMonkey)
+	return levelOfMonkeyBuisness(inspectedItems), nil
+}
 
-		// Skip the blank line between monkeys in the input file
-		fileScanner.Scan()
+// partTwo solves part two with the worry backend mode which must have been checked by partTwoMode
+func partTwo(mode worryMode) (*big.Int, error) {
 
+	monkeys, err := parseInput()
+	if err != nil {
+		return nil, err
 	}
 
-	// track how many items each monkey has inspected
-	inspectedItems := make(map[int]int)
+	// Worry is no longer divided by three so values grow without limit
+	// The actual worry value doesn't matter to the answer. Only the solution to Test which monkey the item is thrown to must remain the same.
+	// Keeping values modulo the least common multiple of all divisors prevents int from overflowing
+	// Problem asks for answer after 10000 rounds of monkey buisness for part two
+	var inspectedItems []int
+	if *engineFlag == "cycles" {
+		inspectedItems, err = simulateCycles(monkeys, *roundsFlag)
+	} else {
+		inspectedItems, err = simulateMode(monkeys, *roundsFlag, 1, mode)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	// Problem asks for answer after 20 rounds of monkey buisness
-	for round := 0; round < 20; round++ {
-		// iterate over each monkey and their items
-		for monkeyId, currentMonkey := range monkeys {
-			for _, item := range currentMonkey.items {
-				// calculate the new worry value for each item
-				newValue := currentMonkey.operation(item)
-				// determine who the item is thrown to
-				throwTo := currentMonkey.testAndThrow(newValue)
-				// append the item to the monkey who receives the item
-				monkeys[throwTo].items = append(monkeys[throwTo].items, newValue)
-			}
+	return levelOfMonkeyBuisness(inspectedItems), nil
+}
 
-			// Count the number of items the currentMonkey has inspected and thrown
-			inspectedItems[monkeyId] += len(monkeys[monkeyId].items)
-			// Once a monkey has inspected and thrown all their items they will have none for this round
-			monkeys[monkeyId].items = []int{}
+// maxExactRounds is the most rounds part two accepts with the big or checked backends
+// Without relief exact worry levels grow so quickly that big takes minutes beyond a few hundred rounds
+// checked can still fail with an overflow well before this depending on the monkeys' operations
+const maxExactRounds = 200
+
+// partTwoMode returns the worry backend for part two from the flags
+// An error is returned for any combination of backend, engine and rounds that can't finish so nothing is simulated first
+func partTwoMode() (worryMode, error) {
+	mode := worryMode(*worryFlag)
+	switch mode {
+	case modMode:
+	case bigMode, checkedMode:
+		if *roundsFlag > maxExactRounds {
+			return "", fmt.Errorf("the %s worry backend supports at most %d rounds, use %s for %d rounds", mode, maxExactRounds, modMode, *roundsFlag)
 		}
+	default:
+		return "", fmt.Errorf("unknown worry mode %q", mode)
 	}
 
-	// Get the level of monkey buisness by multiply the number of items inspected of the top two monkeys
-	var first, second int
-	for _, count := range inspectedItems {
-		if count > second {
-			second = count
-		}
-		if second > first {
-			first, second = second, first
+	switch *engineFlag {
+	case "rounds":
+	case "cycles":
+		// Following items on their own only works with worry kept modulo the divisors
+		if mode != modMode {
+			return "", fmt.Errorf("the cycles engine only supports the %s worry backend", modMode)
 		}
+	default:
+		return "", fmt.Errorf("unknown engine %q", *engineFlag)
 	}
 
-	return first * second, nil
+	return mode, nil
 }
 
-func partTwo() (int, error) {
-
+// parseInput reads the monkeys from the input file
+func parseInput() ([]Monkey, error) {
 	file, err := os.Open("./input.txt")
 	defer file.Close()
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	// monkeys holds all monkeys from the input file
-	var monkeys []Monkey
-
-	// limit large numbers
-	// (a mod kn) = a mod n for any integer k
-	// To keep numbers small we want to keep only numbers around that are modulo the multiple of all divisors to get the k value.
-	// All monkey Test divisors are prime so their least common multiple is just the product of them.
-	// When newValue of worry is created we take the mod of the result of the operation.
-	// This allows checking the Test for all monkeys while also preventing int from overflowing.
-	// The actual worry value doesn't matter to the answer. Only the solution to Test which monkey the item is thrown to must remain the same.
-	var limit int = 1
 
-	// Read the input and parse each monkey's information
-	for fileScanner.Scan() {
+	return parseMonkeys(file)
+}
 
-		var newMonkey Monkey
+// simulate runs the monkeys for the given number of rounds and returns how many items each monkey inspected
+// Worry levels are calculated with the arithmetic a. After each inspection worry is divided by relief unless relief is 1
+func simulate[T any](monkeys []Monkey, rounds, relief int, a arithmetic[T]) ([]int, error) {
 
-		// Read the starting items
-		fileScanner.Scan()
-		// Split the "Starting items: 76, 88, 96, 97, 58, 61, 67" strings by comma without the " Starting items: " text
-		// Then parse each worry value and add it to the monkey's items
-		// all items are in the order they will be inspected by the monkey
-		for _, item := range strings.Split(fileScanner.Text()[len("  Starting items: "):], ", ") {
-			worry, err := strconv.Atoi(item)
+	// Convert the starting items of every monkey into worry levels of the backend
+	// The monkeys are left unchanged so they can be simulated again
+	items := make([][]T, len(monkeys))
+	for monkeyId, m := range monkeys {
+		for _, item := range m.items {
+			worry, err := a.fromInt(item)
 			if err != nil {
-				return -1, err
+				return nil, err
 			}
-			newMonkey.items = append(newMonkey.items, worry)
-		}
-
-		// Read the Operation:
-		fileScanner.Scan()
-		var operator rune
-		var value int = 0
-		// Check if the operation for the monkey inpuit is "old * old" or an "old * value"
-		if fileScanner.Text() == "  Operation: new = old * old" {
-			operator = '*'
-		} else {
-			fmt.Sscanf(fileScanner.Text(), " Operation: new = old %c %d", &operator, &value)
+			items[monkeyId] = append(items[monkeyId], worry)
 		}
-		// Create the Operation function for the monkey Operation input
-		newMonkey.operation = createOperationTwo(operator, value)
-
-		// Read the Test:
-		fileScanner.Scan()
-		var testValue int
-		// Read the test case
-		fmt.Sscanf(fileScanner.Text(), "  Test: divisible by %d", &testValue)
-
-		// set the limit for the modulo
-		// we get a large limit by multiplying all test values for the monkeys together
-		// This is the least common multiple as all monkey Test divisors are prime
-		limit *= testValue
-
-		// Read the true expression to find the monkey the item is thrown to if the test case is true
-		fileScanner.Scan()
-		var monkeyThrownIfTrue int
-		fmt.Sscanf(fileScanner.Text(), "    If true: throw to monkey %d", &monkeyThrownIfTrue)
-
-		// Read the false expression to find the monkey the item is thrown to if the test case is false
-		fileScanner.Scan()
-		var monkeyThrownIfFalse int
-		fmt.Sscanf(fileScanner.Text(), "    If false: throw to monkey %d", &monkeyThrownIfFalse)
-
-		// Create a function that runs the test case
-		newMonkey.testAndThrow = createTest(testValue, monkeyThrownIfTrue, monkeyThrownIfFalse)
-
-		// Add the monkey to the slice of all monkeys
-		monkeys = append(monkeys, newMonkey)
-
-		// Skip the blank line between monkeys in the input file
-		fileScanner.Scan()
+	}
 
+	divideBy, err := a.fromInt(relief)
+	if err != nil {
+		return nil, err
 	}
 
 	// track how many items each monkey has inspected
-	inspectedItems := make(map[int]int)
+	inspectedItems := make([]int, len(monkeys))
 
-	// Problem asks for answer after 10000 rounds of monkey buisness for part two
-	for round := 0; round < 10000; round++ {
+	for round := 0; round < rounds; round++ {
 		// iterate over each monkey and their items
 		for monkeyId, currentMonkey := range monkeys {
-			for _, item := range currentMonkey.items {
+			for _, item := range items[monkeyId] {
 				// calculate the new worry value for each item
-				newValue := currentMonkey.operation(item) % limit
+				newValue, err := evaluate(currentMonkey.operation, item, a)
+				if err != nil {
+					return nil, fmt.Errorf("round %d monkey %d: %w", round+1, monkeyId, err)
+				}
+				if relief != 1 {
+					if newValue, err = a.apply('/', newValue, divideBy); err != nil {
+						return nil, fmt.Errorf("round %d monkey %d: %w", round+1, monkeyId, err)
+					}
+				}
 				// determine who the item is thrown to
-				throwTo := currentMonkey.testAndThrow(newValue)
+				throwTo := currentMonkey.ifFalse
+				if a.divisible(newValue, currentMonkey.divisor) {
+					throwTo = currentMonkey.ifTrue
+				}
 				// append the item to the monkey who receives the item
-				monkeys[throwTo].items = append(monkeys[throwTo].items, newValue)
+				items[throwTo] = append(items[throwTo], newValue)
 			}
 
 			// Count the number of items the currentMonkey has inspected and thrown
-			inspectedItems[monkeyId] += len(monkeys[monkeyId].items)
+			inspectedItems[monkeyId] += len(items[monkeyId])
 			// Once a monkey has inspected and thrown all their items they will have none for this round
-			monkeys[monkeyId].items = []int{}
+			items[monkeyId] = items[monkeyId][:0]
 		}
 	}
 
-	// Get the level of monkey buisness by multiply the number of items inspected of the top two monkeys
+	return inspectedItems, nil
+}
+
+// levelOfMonkeyBuisness multiplies the number of items inspected by the top two monkeys
+// The product is a big.Int as inspection counts over very many rounds can overflow an int when multiplied
+func levelOfMonkeyBuisness(inspectedItems []int) *big.Int {
 	var first, second int
 	for _, count := range inspectedItems {
 		if count > second {
@@ -240,69 +177,17 @@ func partTwo() (int, error) {
 		}
 	}
 
-	return first * second, nil
+	return new(big.Int).Mul(big.NewInt(int64(first)), big.NewInt(int64(second)))
 }
 
-// createOperation is used to create a new function that represents a monkey's Operation from the input file for Part One
-func createOperationOne(operator rune, value int) func(int) int {
-	// func operation takes n which is the worry of an item and returns the new worry of an item
-	operation := func(n int) int {
-		var valueUsed int = value
-		// if valueUsed is zero then it was the old * old operation so value is n
-		if valueUsed == 0 {
-			valueUsed = n
-		}
-
-		if operator == '+' {
-			// Perform the worry addition operation then divide the worry by three in "relief"
-			return (n + valueUsed) / 3
-		}
-
-		// Perform the worry multiplication operation then divide the worry by three in "relief"
-		return (n * valueUsed) / 3
-	}
-
-	// Return the new function of the Monkey "Operation" from the input
-	return operation
-}
-
-// createOperation is used to create a new function that represents a monkey's Operation from the input file for Part Two
-// worry is no longer divided by 3
-func createOperationTwo(operator rune, value int) func(int) int {
-	// func operation takes n which is the worry of an item and returns the new worry of an item
-	operation := func(n int) int {
-		var valueUsed int = value
-		// if valueUsed is zero then it was the old * old operation so value is n
-		if valueUsed == 0 {
-			valueUsed = n
-		}
-
-		if operator == '+' {
-			// Perform the worry addition operation. Worry is no longer divided by 3
-			return (n + valueUsed)
-		}
-
-		// Perform the worry multiplication operation. Worry is no longer divided by 3
-		return (n * valueUsed)
-	}
-
-	// Return the new function of the Monkey "Operation" from the input
-	return operation
-}
+func main() {
+	flag.Parse()
 
-// createTest is used to create a new function that represtents a monkey's Test from the input file
-func createTest(testValue, monkeyThrownIfTrue, monkeyThrownIfFalse int) func(int) int {
-	// func test takes n which is the worry level of an item and returns the number of the monkey the item is thrown to
-	test := func(n int) int {
-		if n%testValue == 0 {
-			return monkeyThrownIfTrue
-		}
-		return monkeyThrownIfFalse
+	mode, err := partTwoMode()
+	if err != nil {
+		log.Fatal(err)
 	}
-	return test
-}
 
-func main() {
 	monkeyBuisness, err := partOne()
 	if err != nil {
 		log.Fatal(err)
@@ -310,7 +195,7 @@ func main() {
 
 	fmt.Println(monkeyBuisness)
 
-	monkeyBuisness, err = partTwo()
+	monkeyBuisness, err = partTwo(mode)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Monkey holds everything read from one monkey's section of the input
+type Monkey struct {
+	id        int   // The number of the monkey from its "Monkey N:" header
+	items     []int // The worry levels of the items the monkey holds in the order they will be inspected
+	operation expr  // How the worry level changes as the monkey inspects an item
+	divisor   int   // The monkey tests if an item's worry level is divisible by this value
+	ifTrue    int   // The monkey the item is thrown to if the test is true
+	ifFalse   int   // The monkey the item is thrown to if the test is false
+}
+
+// monkeyParser reads the input one non blank line at a time and remembers the line number for error messages
+type monkeyParser struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// next returns the next non blank line with surrounding whitespace removed
+// ok is false once there are no more lines
+func (p *monkeyParser) next() (text string, ok bool) {
+	for p.scanner.Scan() {
+		p.line++
+		if text = strings.TrimSpace(p.scanner.Text()); text != "" {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// field reads the next line and returns the text after prefix
+// An error is returned if the line is missing or does not start with prefix
+func (p *monkeyParser) field(prefix string) (string, error) {
+	text, ok := p.next()
+	if !ok {
+		return "", fmt.Errorf("line %d: expected %q but reached the end of the input", p.line, prefix)
+	}
+	if !strings.HasPrefix(text, prefix) {
+		return "", fmt.Errorf("line %d: expected %q but found %q", p.line, prefix, text)
+	}
+	return strings.TrimSpace(text[len(prefix):]), nil
+}
+
+// intField reads the next line and parses the integer after prefix
+func (p *monkeyParser) intField(prefix string) (int, error) {
+	text, err := p.field(prefix)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: %w", p.line, err)
+	}
+	return n, nil
+}
+
+// parseMonkeys reads every monkey from r
+// Monkeys must be numbered from 0 in the order they appear and every throw must target another monkey that exists
+func parseMonkeys(r io.Reader) ([]Monkey, error) {
+	p := monkeyParser{scanner: bufio.NewScanner(r)}
+
+	var monkeys []Monkey
+
+	for {
+		header, ok := p.next()
+		if !ok {
+			break
+		}
+
+		var m Monkey
+
+		// Read the "Monkey N:" header
+		if !strings.HasPrefix(header, "Monkey ") || !strings.HasSuffix(header, ":") {
+			return nil, fmt.Errorf("line %d: expected \"Monkey N:\" but found %q", p.line, header)
+		}
+		id, err := strconv.Atoi(header[len("Monkey ") : len(header)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+		if id != len(monkeys) {
+			return nil, fmt.Errorf("line %d: expected monkey %d but found monkey %d", p.line, len(monkeys), id)
+		}
+		m.id = id
+
+		// Read the starting items. A monkey may start with no items
+		items, err := p.field("Starting items:")
+		if err != nil {
+			return nil, err
+		}
+		if items != "" {
+			for _, item := range strings.Split(items, ",") {
+				worry, err := strconv.Atoi(strings.TrimSpace(item))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", p.line, err)
+				}
+				m.items = append(m.items, worry)
+			}
+		}
+
+		// Read the Operation and parse the right hand side into an expression tree
+		operation, err := p.field("Operation: new =")
+		if err != nil {
+			return nil, err
+		}
+		if m.operation, err = parseExpr(operation); err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+
+		// Read the Test and the monkeys the item is thrown to
+		if m.divisor, err = p.intField("Test: divisible by"); err != nil {
+			return nil, err
+		}
+		if m.divisor <= 0 {
+			return nil, fmt.Errorf("line %d: divisor must be positive but was %d", p.line, m.divisor)
+		}
+		if m.ifTrue, err = p.intField("If true: throw to monkey"); err != nil {
+			return nil, err
+		}
+		if m.ifFalse, err = p.intField("If false: throw to monkey"); err != nil {
+			return nil, err
+		}
+
+		monkeys = append(monkeys, m)
+	}
+
+	if err := p.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(monkeys) == 0 {
+		return nil, fmt.Errorf("no monkeys found in input")
+	}
+
+	// Throw targets can only be checked once the number of monkeys is known
+	for _, m := range monkeys {
+		for _, target := range []int{m.ifTrue, m.ifFalse} {
+			if target < 0 || target >= len(monkeys) {
+				return nil, fmt.Errorf("monkey %d throws to monkey %d which does not exist", m.id, target)
+			}
+			if target == m.id {
+				return nil, fmt.Errorf("monkey %d throws to itself", m.id)
+			}
+		}
+	}
+
+	return monkeys, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// worryMode selects how worry levels are stored and calculated during a run
+type worryMode string
+
+// Use constants for each of the worry number backends
+const (
+	bigMode     worryMode = "big"     // Exact values using math/big. Values grow quickly so this is only practical for small round counts
+	modMode     worryMode = "mod"     // Values are kept modulo the least common multiple of all divisors. Division is not possible
+	checkedMode worryMode = "checked" // Exact values in an int. An error is returned instead of wrapping on overflow
+)
+
+// arithmetic is a backend for worry levels of type T
+// fromInt converts a number from the input into a worry level
+// apply performs one of the operators + - * / on two worry levels
+// divisible reports if a worry level is divisible by d which is used for each monkey's Test
+type arithmetic[T any] interface {
+	fromInt(n int) (T, error)
+	apply(op byte, a, b T) (T, error)
+	divisible(a T, d int) bool
+}
+
+// simulateMode runs simulate for the monkeys using the backend chosen by mode
+func simulateMode(monkeys []Monkey, rounds, relief int, mode worryMode) ([]int, error) {
+	switch mode {
+	case bigMode:
+		return simulate[*big.Int](monkeys, rounds, relief, bigArithmetic{})
+	case modMode:
+		// The Test of every monkey gives the same answer for a and a mod m when m is a multiple of its divisor
+		// Use the least common multiple of the divisors so that the modulus is as small as possible
+		modulus := 1
+		for _, m := range monkeys {
+			modulus = lcm(modulus, m.divisor)
+		}
+		return simulate[int](monkeys, rounds, relief, modArithmetic{modulus: modulus})
+	case checkedMode:
+		return simulate[int](monkeys, rounds, relief, checkedArithmetic{})
+	}
+
+	return nil, fmt.Errorf("unknown worry mode %q", mode)
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// lcm returns the least common multiple of a and b
+func lcm(a, b int) int {
+	return a / gcd(a, b) * b
+}
+
+// bigArithmetic stores exact worry levels in a big.Int
+type bigArithmetic struct{}
+
+func (bigArithmetic) fromInt(n int) (*big.Int, error) {
+	return big.NewInt(int64(n)), nil
+}
+
+func (bigArithmetic) apply(op byte, a, b *big.Int) (*big.Int, error) {
+	switch op {
+	case '+':
+		return new(big.Int).Add(a, b), nil
+	case '-':
+		return new(big.Int).Sub(a, b), nil
+	case '*':
+		return new(big.Int).Mul(a, b), nil
+	case '/':
+		if b.Sign() == 0 {
+			return nil, errors.New("division by zero")
+		}
+		// Quo truncates towards zero to match integer division of an int
+		return new(big.Int).Quo(a, b), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+func (bigArithmetic) divisible(a *big.Int, d int) bool {
+	return new(big.Int).Rem(a, big.NewInt(int64(d))).Sign() == 0
+}
+
+// modArithmetic stores worry levels modulo modulus
+// Every value is kept in the range [0, modulus)
+type modArithmetic struct {
+	modulus int
+}
+
+func (m modArithmetic) fromInt(n int) (int, error) {
+	return (n%m.modulus + m.modulus) % m.modulus, nil
+}
+
+func (m modArithmetic) apply(op byte, a, b int) (int, error) {
+	switch op {
+	case '+':
+		return (a + b) % m.modulus, nil
+	case '-':
+		return (a - b + m.modulus) % m.modulus, nil
+	case '*':
+		// Multiply as 128 bits so that large moduli can't overflow
+		hi, lo := bits.Mul64(uint64(a), uint64(b))
+		return int(bits.Rem64(hi, lo, uint64(m.modulus))), nil
+	case '/':
+		return 0, errors.New("division is not possible in modular mode")
+	}
+	return 0, fmt.Errorf("unknown operator %q", op)
+}
+
+func (m modArithmetic) divisible(a int, d int) bool {
+	return a%d == 0
+}
+
+// checkedArithmetic stores exact worry levels in an int
+// Any operation that would overflow returns an error instead of wrapping around
+type checkedArithmetic struct{}
+
+// errOverflow is returned when a worry level no longer fits in an int
+var errOverflow = errors.New("worry level overflowed int")
+
+func (checkedArithmetic) fromInt(n int) (int, error) {
+	return n, nil
+}
+
+func (checkedArithmetic) apply(op byte, a, b int) (int, error) {
+	switch op {
+	case '+':
+		if (b > 0 && a > math.MaxInt-b) || (b < 0 && a < math.MinInt-b) {
+			return 0, errOverflow
+		}
+		return a + b, nil
+	case '-':
+		if (b < 0 && a > math.MaxInt+b) || (b > 0 && a < math.MinInt+b) {
+			return 0, errOverflow
+		}
+		return a - b, nil
+	case '*':
+		if a == 0 || b == 0 {
+			return 0, nil
+		}
+		c := a * b
+		if c/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+			return 0, errOverflow
+		}
+		return c, nil
+	case '/':
+		if b == 0 {
+			return 0, errors.New("division by zero")
+		}
+		if a == math.MinInt && b == -1 {
+			return 0, errOverflow
+		}
+		return a / b, nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", op)
+}
+
+func (checkedArithmetic) divisible(a int, d int) bool {
+	return a%d == 0
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// diagonals are the extra moves allowed when Rules.Diagonal is set
+var diagonals = []Pair{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// MoveCost returns the cost of stepping from a location with elevation from to a neighbouring location with elevation to
+// ok is false when the step is not allowed
+type MoveCost func(from, to rune) (cost int, ok bool)
+
+// Rules configure how the heightmap can be climbed
+// Diagonal allows steps to the 4 diagonal neighbours as well as the 4 straight ones
+// Cost decides which steps are allowed and how much each one costs
+// MinStepCost and MinHeightCost give a lower bound of every step's cost as MinStepCost + MinHeightCost * |change in elevation|
+// They are used for the A* heuristic. Leaving both as zero makes the search a plain Dijkstra
+type Rules struct {
+	Diagonal      bool
+	Cost          MoveCost
+	MinStepCost   int
+	MinHeightCost int
+}
+
+// LimitedCost returns a MoveCost that allows climbing at most maxClimb and descending at most maxDescent in a single step
+// A negative maxDescent allows any descent
+// Each step costs stepCost plus heightCost for every level of elevation changed
+func LimitedCost(maxClimb, maxDescent, stepCost, heightCost int) MoveCost {
+	return func(from, to rune) (int, bool) {
+		change := int(to - from)
+		if change > maxClimb {
+			return 0, false
+		}
+		if maxDescent >= 0 && -change > maxDescent {
+			return 0, false
+		}
+		return stepCost + heightCost*abs(change), true
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// moves returns the steps that can be taken from a location under rules r
+func (r Rules) moves() []Pair {
+	if r.Diagonal {
+		return append(append([]Pair(nil), neighbours...), diagonals...)
+	}
+	return neighbours
+}
+
+// heuristic is a lower bound of the cost from p to the end used by A*
+// Each step costs at least MinStepCost and covers one column or row. With diagonal steps it can cover one of each
+// Every level of elevation between p and the end also costs at least MinHeightCost
+func (h Heightmap) heuristic(p Pair, r Rules) int {
+	dx, dy := abs(h.end.x-p.x), abs(h.end.y-p.y)
+	steps := dx + dy
+	if r.Diagonal {
+		steps = dx
+		if dy > dx {
+			steps = dy
+		}
+	}
+	climb := abs(int(h.elevation[h.end.y][h.end.x] - h.elevation[p.y][p.x]))
+
+	return r.MinStepCost*steps + r.MinHeightCost*climb
+}
+
+// cheapestPath finds the minimum cost route to the end starting from any of the starts using A*
+// It returns the locations on the route including both ends and the total cost of the route
+func (h Heightmap) cheapestPath(starts []Pair, r Rules) ([]Pair, int, error) {
+	if r.Cost == nil {
+		return nil, 0, errors.New("rules must have a Cost function")
+	}
+
+	// cost holds the cheapest known cost to reach each location or -1 if it hasn't been reached
+	// previous is the location before each location on its cheapest known route
+	cost := make([][]int, h.height)
+	previous := make([][]Pair, h.height)
+	for y := range cost {
+		cost[y] = make([]int, h.width)
+		previous[y] = make([]Pair, h.width)
+		for x := range cost[y] {
+			cost[y][x] = -1
+		}
+	}
+
+	// toVisit is ordered by the cost so far plus the heuristic so the most promising location is visited first
+	toVisit := &locationQueue{}
+	for _, start := range starts {
+		cost[start.y][start.x] = 0
+		previous[start.y][start.x] = start
+		heap.Push(toVisit, queuedLocation{location: start, priority: h.heuristic(start, r)})
+	}
+
+	moves := r.moves()
+	for toVisit.Len() > 0 {
+		item := heap.Pop(toVisit).(queuedLocation)
+		currentLocation := item.location
+		currentCost := cost[currentLocation.y][currentLocation.x]
+
+		// Skip stale queue entries for locations that have since been reached more cheaply
+		if item.priority > currentCost+h.heuristic(currentLocation, r) {
+			continue
+		}
+
+		// With an admissible heuristic the first time the end is taken from the queue its cost is the minimum
+		if currentLocation == h.end {
+			path := []Pair{currentLocation}
+			for current := currentLocation; previous[current.y][current.x] != current; {
+				current = previous[current.y][current.x]
+				path = append(path, current)
+			}
+			// The path was built from the end so reverse it
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, currentCost, nil
+		}
+
+		for _, move := range moves {
+			nextLocation := Pair{currentLocation.x + move.x, currentLocation.y + move.y}
+			if !h.inBounds(nextLocation) {
+				continue
+			}
+
+			stepCost, ok := r.Cost(h.elevation[currentLocation.y][currentLocation.x], h.elevation[nextLocation.y][nextLocation.x])
+			if !ok {
+				continue
+			}
+
+			nextCost := currentCost + stepCost
+			if known := cost[nextLocation.y][nextLocation.x]; known >= 0 && known <= nextCost {
+				continue
+			}
+
+			cost[nextLocation.y][nextLocation.x] = nextCost
+			previous[nextLocation.y][nextLocation.x] = currentLocation
+			heap.Push(toVisit, queuedLocation{location: nextLocation, priority: nextCost + h.heuristic(nextLocation, r)})
+		}
+	}
+
+	return nil, 0, errors.New("the end can't be reached from any start")
+}
+
+// queuedLocation is a location waiting in the A* queue with its priority
+type queuedLocation struct {
+	location Pair
+	priority int
+}
+
+// locationQueue is a min heap of locations ordered by priority for use with container/heap
+type locationQueue []queuedLocation
+
+func (q locationQueue) Len() int           { return len(q) }
+func (q locationQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q locationQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *locationQueue) Push(x any) {
+	*q = append(*q, x.(queuedLocation))
+}
+
+func (q *locationQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -2,10 +2,27 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"sort"
+	"strings"
+)
+
+// draw prints the heightmap with each path drawn on it when set
+var draw = flag.Bool("draw", false, "print the heightmap with the path drawn on it")
+
+// search selects the solver. "bfs" uses the puzzle rules while "astar" uses the climbing rules from the flags below
+var search = flag.String("search", "bfs", "solver to use: bfs or astar")
+
+// The climbing rules used by the astar search. The defaults match the puzzle
+var (
+	diagonal   = flag.Bool("diagonal", false, "allow diagonal steps")
+	maxClimb   = flag.Int("climb", 1, "largest elevation increase in one step")
+	maxDescent = flag.Int("descent", -1, "largest elevation decrease in one step, negative for no limit")
+	stepCost   = flag.Int("step-cost", 1, "cost of every step")
+	heightCost = flag.Int("height-cost", 0, "extra cost per level of elevation changed in a step")
 )
 
 // Pair represents a pair of x and y coordinates
@@ -13,20 +30,59 @@ type Pair struct {
 	x, y int
 }
 
-func partOne() (int, error) {
+// neighbours are the moves from a location to the 4 locations next to it
+var neighbours = []Pair{{1, 0}, {0, -1}, {-1, 0}, {0, 1}}
+
+// Heightmap represents an x y grid of elevation values
+// start, end mark the start position and the end destination
+type Heightmap struct {
+	elevation     [][]rune
+	start, end    Pair
+	width, height int
+}
+
+// Routes holds the result of searching backwards from the end of the heightmap
+// distance is the number of steps from each location to the end or -1 if the end can't be reached from that location
+// next is the location to step to from each location to follow a shortest path to the end
+type Routes struct {
+	distance [][]int
+	next     [][]Pair
+}
+
+// solver finds a route to the end from any of the starts and returns its locations and cost
+type solver func(starts []Pair) ([]Pair, int, error)
+
+func partOne(h Heightmap, solve solver) ([]Pair, int, error) {
+	// The path must begin at the start location marked S
+	return solve([]Pair{h.start})
+}
+
+func partTwo(h Heightmap, solve solver) ([]Pair, int, error) {
+	// The path can begin at any location with an elevation of a
+	var lowElevationStarts []Pair
+	for y, row := range h.elevation {
+		for x, elevation := range row {
+			if elevation == 'a' {
+				lowElevationStarts = append(lowElevationStarts, Pair{x, y})
+			}
+		}
+	}
+
+	return solve(lowElevationStarts)
+}
+
+// parseInput reads the heightmap from the input file
+func parseInput() (Heightmap, error) {
+	var h Heightmap
 
 	file, err := os.Open("./input.txt")
 	defer file.Close()
 	if err != nil {
-		return -1, err
+		return h, err
 	}
 	fileScanner := bufio.NewScanner(file)
 
-	// heightmap represents and x y grid of elevation values
-	heightmap := make([][]rune, 0)
-
-	// start, end mark the start position and the end destination
-	var start, end Pair
+	var foundStart, foundEnd bool
 
 	// Create the heightmap grid
 	for fileScanner.Scan() {
@@ -35,185 +91,236 @@ func partOne() (int, error) {
 		for index, elevation := range fileScanner.Text() {
 
 			// When the start location is found set that pair. Adjust the elevation defined in the problem
-			// len(heightmap) is also the current row number
+			// len(h.elevation) is also the current row number
 			if elevation == 'S' {
-				start = Pair{index, len(heightmap)}
+				h.start = Pair{index, len(h.elevation)}
 				elevation = 'a'
+				foundStart = true
 			}
 
 			// When the end location is found set that pair. Adjust the elevation defined in the problem
 			if elevation == 'E' {
-				end = Pair{index, len(heightmap)}
+				h.end = Pair{index, len(h.elevation)}
 				elevation = 'z'
+				foundEnd = true
+			}
+
+			if elevation < 'a' || elevation > 'z' {
+				return h, fmt.Errorf("unknown elevation %q at %d,%d", elevation, index, len(h.elevation))
 			}
 
 			currentRow = append(currentRow, elevation)
 		}
 
-		heightmap = append(heightmap, currentRow)
-	}
-
-	// toVisit holds the locations to be visited
-	toVisit := []Pair{start}
-	// visited is a map of all locations that have already been visited
-	visited := make(map[Pair]bool)
-	// distanceTravelled maps each Pair of x,y coordinates to the distance from the starting point
-	distanceTravelled := map[Pair]int{start: 0}
-
-	heightmapHeight := len(heightmap)
-	heightmapWidth := len(heightmap[0])
-
-	for {
-		// The current location is the one that is visited next
-		// After the start location all currentLocation will be the location with the shortest distanceTravelled
-		currentLocation := toVisit[0]
-		// mark the current location as visited
-		visited[currentLocation] = true
-		// remove the current location from the locations to be visited
-		toVisit = toVisit[1:]
-
-		// If the end location is found return the distance from the start to the end
-		if currentLocation == end {
-			return distanceTravelled[end], nil
+		if len(h.elevation) > 0 && len(currentRow) != len(h.elevation[0]) {
+			return h, fmt.Errorf("row %d has width %d, expected %d", len(h.elevation), len(currentRow), len(h.elevation[0]))
 		}
 
-		// For each location check the 4 neighbour locations to that point
-		neighbours := []Pair{{1, 0}, {0, -1}, {-1, 0}, {0, 1}}
-		for _, nearby := range neighbours {
-			moveX, moveY := nearby.x, nearby.y
-			nextLocation := Pair{currentLocation.x + moveX, currentLocation.y + moveY}
+		h.elevation = append(h.elevation, currentRow)
+	}
 
-			// Check that the nextLocation hasn't been visited, is within the heightmap bounds, And that the elevation of the destination is no more than 1 greater than the current location so that it is a valid possible location
-			if !visited[nextLocation] && nextLocation.x >= 0 && nextLocation.y >= 0 && nextLocation.x < heightmapWidth && nextLocation.y < heightmapHeight && (heightmap[nextLocation.y][nextLocation.x]-heightmap[currentLocation.y][currentLocation.x] <= 1) {
+	if !foundStart || !foundEnd {
+		return h, errors.New("heightmap must contain a start S and an end E")
+	}
 
-				// If the nextLocation has a distance of 0 from the start it hasn't been visited. Set its distanceTravelled from the start to one greater than the previous location
-				// Add this neighbour as a location that needs to be visited
-				if distanceTravelled[nextLocation] == 0 {
-					toVisit = append(toVisit, nextLocation)
-					distanceTravelled[nextLocation] = distanceTravelled[currentLocation] + 1
-				}
-			}
-		}
+	h.height = len(h.elevation)
+	h.width = len(h.elevation[0])
 
-		// Sort all locations to be visited from shortest distance to longest
-		sort.Slice(toVisit, func(x, y int) bool {
-			return distanceTravelled[toVisit[x]] < distanceTravelled[toVisit[y]]
-		})
-	}
+	return h, nil
 }
 
-func partTwo() (int, error) {
+// inBounds returns true if p is a location on the heightmap
+func (h Heightmap) inBounds(p Pair) bool {
+	return p.x >= 0 && p.y >= 0 && p.x < h.width && p.y < h.height
+}
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
-	if err != nil {
-		return -1, err
+// reverseSearch runs a single breadth first search backwards from the end location
+// A step from a location to its neighbour is allowed when the neighbour is at most one higher
+// Searching backwards means a location is added when the step from it to the current location is allowed
+// Every location is visited at most once so the whole search takes time proportional to the number of locations
+func (h Heightmap) reverseSearch() Routes {
+	r := Routes{
+		distance: make([][]int, h.height),
+		next:     make([][]Pair, h.height),
+	}
+	for y := range r.distance {
+		r.distance[y] = make([]int, h.width)
+		r.next[y] = make([]Pair, h.width)
+		for x := range r.distance[y] {
+			r.distance[y][x] = -1
+		}
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	// heightmap represents and x y grid of elevation values
-	heightmap := make([][]rune, 0)
-
-	// start, end mark the start position and the end destination
-	var end Pair
 
-	// lowElectationsStarts holds all locations that have an elevation of a and are potential start locations for the trail
-	var lowElevationStarts []Pair
+	// toVisit is a queue of locations in the order they were reached so their distances never decrease
+	toVisit := []Pair{h.end}
+	r.distance[h.end.y][h.end.x] = 0
 
-	// Create the heightmap grid
-	for fileScanner.Scan() {
-		// currentRow is a row in the heightmap
-		var currentRow []rune
-		for index, elevation := range fileScanner.Text() {
+	for len(toVisit) > 0 {
+		currentLocation := toVisit[0]
+		toVisit = toVisit[1:]
 
-			// When the start location is found set that pair. Adjust the elevation defined in the problem
-			// len(heightmap) is also the current row number
-			if elevation == 'S' || elevation == 'a' {
-				elevation = 'a'
-				lowElevationStarts = append(lowElevationStarts, Pair{index, len(heightmap)})
+		for _, nearby := range neighbours {
+			previousLocation := Pair{currentLocation.x + nearby.x, currentLocation.y + nearby.y}
 
+			// Skip locations off the heightmap and locations that already have a distance
+			if !h.inBounds(previousLocation) || r.distance[previousLocation.y][previousLocation.x] >= 0 {
+				continue
 			}
 
-			// When the end location is found set that pair. Adjust the elevation defined in the problem
-			if elevation == 'E' {
-				end = Pair{index, len(heightmap)}
-				elevation = 'z'
+			// The step from previousLocation up to currentLocation can climb at most one
+			if h.elevation[currentLocation.y][currentLocation.x]-h.elevation[previousLocation.y][previousLocation.x] > 1 {
+				continue
 			}
 
-			currentRow = append(currentRow, elevation)
+			r.distance[previousLocation.y][previousLocation.x] = r.distance[currentLocation.y][currentLocation.x] + 1
+			r.next[previousLocation.y][previousLocation.x] = currentLocation
+			toVisit = append(toVisit, previousLocation)
 		}
+	}
+
+	return r
+}
 
-		heightmap = append(heightmap, currentRow)
+// bestPath returns the shortest path from any of the starts to the end and the number of steps it takes
+// The search from the end already knows the distance from every location so the closest start is picked
+func (r Routes) bestPath(starts []Pair) ([]Pair, int, error) {
+	best := Pair{-1, -1}
+	for _, start := range starts {
+		if r.distance[start.y][start.x] < 0 {
+			continue
+		}
+		if best.x < 0 || r.distance[start.y][start.x] < r.distance[best.y][best.x] {
+			best = start
+		}
 	}
 
-	var shortestPath int
-	heightmapHeight := len(heightmap)
-	heightmapWidth := len(heightmap[0])
+	if best.x < 0 {
+		return nil, 0, errors.New("the end can't be reached from any start")
+	}
 
-	for _, startLocation := range lowElevationStarts {
-		visited := make(map[Pair]bool)
-		toVisit := []Pair{startLocation}
-		distanceTravelled := map[Pair]int{startLocation: 0}
+	path := []Pair{best}
+	for current := best; r.distance[current.y][current.x] > 0; {
+		current = r.next[current.y][current.x]
+		path = append(path, current)
+	}
 
-		for {
-			// If no unvisited nodes remain then this startLocation cannot reach the end location
-			if len(toVisit) == 0 {
-				break
-			}
+	return path, r.distance[best.y][best.x], nil
+}
 
-			currentLocation := toVisit[0]
-			visited[currentLocation] = true
-			toVisit = toVisit[1:]
+// drawPath returns the heightmap with the path drawn on it
+// Each location on the path shows the direction of the next step with > < ^ v or / \ for diagonal steps and the end is drawn as E
+func (h Heightmap) drawPath(path []Pair) string {
+	grid := make([][]rune, h.height)
+	for y, row := range h.elevation {
+		grid[y] = append([]rune(nil), row...)
+	}
 
-			// If the end location is found check what the current shortest distance found is
-			if currentLocation == end {
-				// If the shortest distance is 0 this is the first complete path.
-				// If this path is shorter than the previous shortest path update the length of the shortest path
-				if distanceTravelled[end] < shortestPath || shortestPath == 0 {
-					shortestPath = distanceTravelled[end]
-				}
-				// move to the next startLocation
-				break
+	for i := 0; i+1 < len(path); i++ {
+		current, next := path[i], path[i+1]
+		switch {
+		case next.x != current.x && next.y != current.y:
+			if (next.x > current.x) == (next.y < current.y) {
+				grid[current.y][current.x] = '/'
+			} else {
+				grid[current.y][current.x] = '\\'
 			}
+		case next.x > current.x:
+			grid[current.y][current.x] = '>'
+		case next.x < current.x:
+			grid[current.y][current.x] = '<'
+		case next.y < current.y:
+			grid[current.y][current.x] = '^'
+		default:
+			grid[current.y][current.x] = 'v'
+		}
+	}
+	grid[h.end.y][h.end.x] = 'E'
 
-			// Checking neighbours is the same as part 1
-			neighbours := []Pair{{1, 0}, {0, -1}, {-1, 0}, {0, 1}}
-			for _, nearby := range neighbours {
-				moveX, moveY := nearby.x, nearby.y
-				nextLocation := Pair{currentLocation.x + moveX, currentLocation.y + moveY}
+	var b strings.Builder
+	for _, row := range grid {
+		b.WriteString(string(row))
+		b.WriteByte('\n')
+	}
 
-				if !visited[nextLocation] && nextLocation.x >= 0 && nextLocation.y >= 0 && nextLocation.x < heightmapWidth && nextLocation.y < heightmapHeight && (heightmap[nextLocation.y][nextLocation.x]-heightmap[currentLocation.y][currentLocation.x] <= 1) {
+	return b.String()
+}
 
-					if distanceTravelled[nextLocation] == 0 {
-						toVisit = append(toVisit, nextLocation)
-						distanceTravelled[nextLocation] = distanceTravelled[currentLocation] + 1
-					}
+// ruleFlags are the flags that set the climbing rules. They only apply to the astar search
+var ruleFlags = []string{"diagonal", "climb", "descent", "step-cost", "height-cost"}
+
+// newSolver returns the solver chosen by the flags after checking the climbing rules make sense for it
+func newSolver(heightmap Heightmap) (solver, error) {
+	switch *search {
+	case "bfs":
+		// The breadth first search always uses the puzzle rules so setting any rule would be silently ignored
+		var err error
+		flag.Visit(func(f *flag.Flag) {
+			for _, name := range ruleFlags {
+				if f.Name == name && err == nil {
+					err = fmt.Errorf("-%s only applies to -search astar", name)
 				}
 			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// One search from the end answers both parts
+		return heightmap.reverseSearch().bestPath, nil
+	case "astar":
+		// A negative cost would make the heuristic overestimate and the search miss the cheapest path
+		if *stepCost < 0 {
+			return nil, fmt.Errorf("step-cost can't be negative, got %d", *stepCost)
+		}
+		if *heightCost < 0 {
+			return nil, fmt.Errorf("height-cost can't be negative, got %d", *heightCost)
+		}
 
-			// Sorting by shortest distanceTravelled is the same as part 1
-			sort.Slice(toVisit, func(x, y int) bool {
-				return distanceTravelled[toVisit[x]] < distanceTravelled[toVisit[y]]
-			})
+		rules := Rules{
+			Diagonal:      *diagonal,
+			Cost:          LimitedCost(*maxClimb, *maxDescent, *stepCost, *heightCost),
+			MinStepCost:   *stepCost,
+			MinHeightCost: *heightCost,
 		}
+		return func(starts []Pair) ([]Pair, int, error) {
+			return heightmap.cheapestPath(starts, rules)
+		}, nil
 	}
 
-	return shortestPath, nil
+	return nil, fmt.Errorf("unknown search %q", *search)
 }
 
 func main() {
-	distance, err := partOne()
+	flag.Parse()
+
+	heightmap, err := parseInput()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println(distance)
+	solve, err := newSolver(heightmap)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	distance, err = partTwo()
+	path, cost, err := partOne(heightmap, solve)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println(distance)
+	fmt.Println(cost)
+	if *draw {
+		fmt.Print(heightmap.drawPath(path))
+	}
+
+	path, cost, err = partTwo(heightmap, solve)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(cost)
+	if *draw {
+		fmt.Print(heightmap.drawPath(path))
+	}
 
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// generatePackets creates n random packets
+// The same seed always creates the same packets so benchmark runs can be compared
+// Some packets compare equal to a divider, such as [2] or [[[6]]], which both modes must handle the same way
+func generatePackets(n int, seed int64) Packets {
+	r := rand.New(rand.NewSource(seed))
+	packets := make(Packets, n)
+	for i := range packets {
+		packets[i] = randomList(r, 0)
+	}
+	return packets
+}
+
+// randomList creates a random list packet nested at most 4 lists deep
+// Each list holds up to 5 values. The deeper the list the more likely a value is an integer
+func randomList(r *rand.Rand, depth int) Packet {
+	list := List()
+	for i := r.Intn(6); i > 0; i-- {
+		if depth < 4 && r.Intn(depth+2) == 0 {
+			list.list = append(list.list, randomList(r, depth+1))
+		} else {
+			list.list = append(list.list, Int(r.Intn(11)))
+		}
+	}
+	return list
+}
+
+// benchmarkPackets is the number of generated packets each benchmark finds the decoder key of
+const benchmarkPackets = 10000
+
+// TestDecoderKeyModesAgree checks sorting and ranking find the same decoder key for generated packets
+func TestDecoderKeyModesAgree(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 1000} {
+		packets := generatePackets(n, 2022)
+		if sorted, ranked := decoderKeyBySort(packets), decoderKeyByRank(packets); sorted != ranked {
+			t.Errorf("%d packets: modes disagree: sort %d rank %d", n, sorted, ranked)
+		}
+	}
+}
+
+// TestDecoderKey checks both modes on packets that compare equal to the dividers
+// Each divider is placed before the packets equal to it and those packets never count towards the key
+func TestDecoderKey(t *testing.T) {
+	tests := []struct {
+		packets []string
+		want    int
+	}{
+		{[]string{"[1]", "[3]"}, 2 * 4},
+		{[]string{"[1]", "[2]", "[3]", "[[6]]"}, 2 * 5},
+		{[]string{"[2]", "[[[6]]]"}, 1 * 3},
+		{[]string{"[[2]]", "[[6]]", "[[2]]", "[[6]]"}, 1 * 4},
+	}
+
+	for _, tt := range tests {
+		var packets Packets
+		for _, s := range tt.packets {
+			packet, err := ParsePacket(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			packets = append(packets, packet)
+		}
+
+		if got := decoderKeyBySort(packets); got != tt.want {
+			t.Errorf("%v: sort found %d, want %d", tt.packets, got, tt.want)
+		}
+		if got := decoderKeyByRank(packets); got != tt.want {
+			t.Errorf("%v: rank found %d, want %d", tt.packets, got, tt.want)
+		}
+	}
+}
+
+// benchmarkDecoderKey times finding the decoder key of generated packets with decoderKey
+// It fails if the result differs from ranking so both benchmarks are timing the same answer
+func benchmarkDecoderKey(b *testing.B, decoderKey func(Packets) int) {
+	packets := generatePackets(benchmarkPackets, 2022)
+	want := decoderKeyByRank(packets)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if got := decoderKey(packets); got != want {
+			b.Fatalf("decoder key %d but ranking found %d", got, want)
+		}
+	}
+}
+
+func BenchmarkDecoderKeyBySort(b *testing.B) {
+	benchmarkDecoderKey(b, decoderKeyBySort)
+}
+
+func BenchmarkDecoderKeyByRank(b *testing.B) {
+	benchmarkDecoderKey(b, decoderKeyByRank)
+}
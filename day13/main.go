@@ -2,139 +2,134 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"sort"
 )
 
+// mode selects how the decoder key is found in part two
+var mode = flag.String("mode", "rank", "how to find the decoder key: rank or sort")
+
+// The divider packets added to the packets in part two
+var dividers = Packets{
+	List(List(Int(2))),
+	List(List(Int(6))),
+}
+
 func partOne() (int, error) {
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
+	packets, err := parseInput()
 	if err != nil {
 		return -1, err
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	index, totalSum := 1, 0
-
-	for fileScanner.Scan() {
-
-		// Use Go's builtin JSON unmarshal to read each line into any
-		var lineOne, lineTwo any
-
-		// Read the first line
-		json.Unmarshal([]byte(fileScanner.Text()), &lineOne)
 
-		// Read the second line
-		fileScanner.Scan()
-		json.Unmarshal(fileScanner.Bytes(), &lineTwo)
-
-		// skip the blank line
-		fileScanner.Scan()
-
-		if compare(lineOne, lineTwo) <= 0 {
-			totalSum += index
+	// Packets come in pairs. Add up the index of each pair that is in the right order
+	// Pairs are indexed from 1
+	totalSum := 0
+	for i := 0; i+1 < len(packets); i += 2 {
+		if packets[i].Compare(packets[i+1]) <= 0 {
+			totalSum += i/2 + 1
 		}
-
-		index++
 	}
 
 	return totalSum, nil
 }
 
 func partTwo() (int, error) {
-	file, err := os.Open("./input.txt")
-	defer file.Close()
+
+	packets, err := parseInput()
 	if err != nil {
 		return -1, err
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	// packets holds each line of input packet
-	packets := []any{}
-
-	for fileScanner.Scan() {
 
-		// Use Go's builtin JSON unmarshal to read each line into any
-		var lineOne, lineTwo any
-
-		// Read the first line
-		json.Unmarshal([]byte(fileScanner.Text()), &lineOne)
-
-		// Read the second line
-		fileScanner.Scan()
-		json.Unmarshal(fileScanner.Bytes(), &lineTwo)
-
-		// skip the blank line
-		fileScanner.Scan()
-
-		// Add both input lines to the packets
-		packets = append(packets, lineOne, lineTwo)
+	switch *mode {
+	case "rank":
+		return decoderKeyByRank(packets), nil
+	case "sort":
+		return decoderKeyBySort(packets), nil
 	}
 
-	// add the divider packets with floats to match jsonUnmarshal
-	packets = append(packets, []any{[]any{2.0}}, []any{[]any{6.0}})
+	return -1, fmt.Errorf("unknown mode %q", *mode)
+}
 
-	// sort the slice of packets by comparing each index
-	sort.Slice(packets, func(i, j int) bool {
-		return compare(packets[i], packets[j]) < 0
+// decoderKeyBySort sorts the packets with the dividers added and multiplies the indices of the dividers
+// Packets can compare equal to a divider, such as [2] and [[2]], so the dividers are found by where they started
+// rather than by comparing them. They are added in front and sorted stably so each comes before any packet equal to it
+func decoderKeyBySort(packets Packets) int {
+	// Add the divider packets to a copy so the packets passed in are left unchanged
+	all := append(append(Packets(nil), dividers...), packets...)
+
+	// order[i] is the index in all of the packet that is i-th once sorted into the right order
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return all[order[i]].Compare(all[order[j]]) < 0
 	})
 
-	// find the decoder packets
+	// find the divider packets
 	decoderKey := 1
-	for i, pkt := range packets {
+	for i, index := range order {
 		// When the packets are found multiply their index by the other
-		if fmt.Sprint(pkt) == "[[2]]" || fmt.Sprint(pkt) == "[[6]]" {
+		if index < len(dividers) {
 			decoderKey *= i + 1
 		}
 	}
 
-	return decoderKey, nil
+	return decoderKey
 }
 
-// compare takes two lines of input
-// If the int returned is 0 or lower then the values are in the correct order
-// If the int returned is over 0 then the values are in the wrong order
-// If the int returned is 0 then the values are the same
-func compare(lineOne, lineTwo any) int {
-	// Use a type assertion to check if the current section of lineOne and lineTwo are slices
-	sliceOne, oneOk := lineOne.([]any)
-	sliceTwo, twoOk := lineTwo.([]any)
-
-	switch {
-	// If neither are slices because the type assertion fails then they are both values
-	// jsonUnmarshal from the input reading section uses float64 for JSON numbers
-	case !oneOk && !twoOk:
-		// If the first value is larger this will return a positive number meaning the values are NOT in the right order
-		// The lower value should be on the left (lineOne)
-		return int(lineOne.(float64) - lineTwo.(float64))
-	// If just one type assertion failes then one line is a list of values and the other is a value
-	// Create a list from the value to compare against the other
-	case !oneOk:
-		sliceOne = []any{lineOne}
-	case !twoOk:
-		sliceTwo = []any{lineTwo}
+// decoderKeyByRank multiplies the indices the dividers would have once sorted without sorting the packets
+// The index of a divider is one more than the number of packets and other dividers that come before it
+// Packets equal to a divider are not counted, matching decoderKeyBySort which places each divider before them
+// Counting those takes a single pass over the packets for each divider
+func decoderKeyByRank(packets Packets) int {
+	decoderKey := 1
+	for _, divider := range dividers {
+		decoderKey *= packets.Rank(divider) + dividers.Rank(divider) + 1
 	}
 
-	// While there are values to compare in both lists
-	for i := 0; i < len(sliceOne) && i < len(sliceTwo); i++ {
-		// Compare each potential list inside of the currentlists recursively
-		// If 0 is returned then the values are the same so move to the next
-		// If there was a difference in values then return it
-		if c := compare(sliceOne[i], sliceTwo[i]); c != 0 {
-			return c
+	return decoderKey
+}
+
+// parseInput reads every packet from the input file in order
+// Blank lines between pairs of packets are skipped
+func parseInput() (Packets, error) {
+	file, err := os.Open("./input.txt")
+	defer file.Close()
+	if err != nil {
+		return nil, err
+	}
+	fileScanner := bufio.NewScanner(file)
+
+	var packets Packets
+	line := 0
+	for fileScanner.Scan() {
+		line++
+		if fileScanner.Text() == "" {
+			continue
 		}
+
+		packet, err := ParsePacket(fileScanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		packets = append(packets, packet)
 	}
 
-	// sliceOne must have less values than sliceTwo for the lists to be in the right order. This would create a negative values
-	// if sliceOne is longer it will not be in the correct order and return a positive
-	return len(sliceOne) - len(sliceTwo)
+	if len(packets)%2 != 0 {
+		return nil, fmt.Errorf("expected pairs of packets but found %d packets", len(packets))
+	}
+
+	return packets, fileScanner.Err()
 }
 
 func main() {
+	flag.Parse()
+
 	orderedCorrect, err := partOne()
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Packet is either an integer or a list of packets
+// isList separates an empty list from the integer 0
+type Packet struct {
+	isList bool
+	value  int
+	list   []Packet
+}
+
+// Int creates a packet holding the integer n
+func Int(n int) Packet {
+	return Packet{value: n}
+}
+
+// List creates a packet holding a list of the packets ps
+func List(ps ...Packet) Packet {
+	return Packet{isList: true, list: ps}
+}
+
+// IsList returns true if p is a list and false if it is an integer
+func (p Packet) IsList() bool {
+	return p.isList
+}
+
+// Compare returns the order of packets p and q
+// A negative number means p comes before q and they are in the right order
+// A positive number means p comes after q and they are in the wrong order
+// Zero means the packets are the same
+func (p Packet) Compare(q Packet) int {
+	switch {
+	// If both are integers the lower integer should come first
+	case !p.isList && !q.isList:
+		switch {
+		case p.value < q.value:
+			return -1
+		case p.value > q.value:
+			return 1
+		}
+		return 0
+	// If exactly one value is an integer convert it to a list containing only that integer and compare again
+	case !p.isList:
+		return List(p).Compare(q)
+	case !q.isList:
+		return p.Compare(List(q))
+	}
+
+	// Compare the lists one value at a time. The first difference decides the order
+	for i := 0; i < len(p.list) && i < len(q.list); i++ {
+		if c := p.list[i].Compare(q.list[i]); c != 0 {
+			return c
+		}
+	}
+
+	// If one list runs out first it should come first
+	switch {
+	case len(p.list) < len(q.list):
+		return -1
+	case len(p.list) > len(q.list):
+		return 1
+	}
+	return 0
+}
+
+// String returns the packet written the same way as the input such as "[1,[2,3],[]]"
+func (p Packet) String() string {
+	var b strings.Builder
+	p.write(&b)
+	return b.String()
+}
+
+// write adds the text of p to b
+func (p Packet) write(b *strings.Builder) {
+	if !p.isList {
+		b.WriteString(strconv.Itoa(p.value))
+		return
+	}
+
+	b.WriteByte('[')
+	for i, item := range p.list {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		item.write(b)
+	}
+	b.WriteByte(']')
+}
+
+// ParsePacket reads a packet from its text such as "[1,[2,3],[]]"
+// An error is returned if the text is not exactly one valid packet
+func ParsePacket(s string) (Packet, error) {
+	p := packetParser{input: s}
+	packet, err := p.parse()
+	if err != nil {
+		return Packet{}, err
+	}
+	if p.pos != len(s) {
+		return Packet{}, fmt.Errorf("unexpected %q at position %d in %q", s[p.pos], p.pos, s)
+	}
+	return packet, nil
+}
+
+// packetParser is a recursive descent parser over the text of a packet
+// pos is the index of the next byte to read from input
+type packetParser struct {
+	input string
+	pos   int
+}
+
+// parse reads an integer or a list starting at pos
+func (p *packetParser) parse() (Packet, error) {
+	if p.pos >= len(p.input) {
+		return Packet{}, fmt.Errorf("unexpected end of packet %q", p.input)
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '[':
+		return p.parseList()
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		n, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil {
+			return Packet{}, err
+		}
+		return Int(n), nil
+	}
+
+	return Packet{}, fmt.Errorf("unexpected %q at position %d in %q", c, p.pos, p.input)
+}
+
+// parseList reads a list of packets separated by commas inside square brackets
+func (p *packetParser) parseList() (Packet, error) {
+	// Skip the opening bracket
+	p.pos++
+
+	list := List()
+	if p.pos < len(p.input) && p.input[p.pos] == ']' {
+		p.pos++
+		return list, nil
+	}
+
+	for {
+		item, err := p.parse()
+		if err != nil {
+			return Packet{}, err
+		}
+		list.list = append(list.list, item)
+
+		if p.pos >= len(p.input) {
+			return Packet{}, fmt.Errorf("missing ']' in %q", p.input)
+		}
+
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return Packet{}, fmt.Errorf("unexpected %q at position %d in %q", p.input[p.pos], p.pos, p.input)
+		}
+	}
+}
+
+// Packets is a slice of packets that can be sorted into the right order with sort.Sort
+type Packets []Packet
+
+func (ps Packets) Len() int           { return len(ps) }
+func (ps Packets) Less(i, j int) bool { return ps[i].Compare(ps[j]) < 0 }
+func (ps Packets) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
+
+// Rank returns how many packets in ps come before p in the right order
+// It is the zero based index p would have if it was added to the front of ps and sorted stably, found without sorting
+// Packets equal to p are not counted so p is placed before them
+func (ps Packets) Rank(p Packet) int {
+	rank := 0
+	for _, q := range ps {
+		if q.Compare(p) < 0 {
+			rank++
+		}
+	}
+	return rank
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pair represents a pair of x and y coordinates
+type Pair struct {
+	x, y int
+}
+
+// What can be in each location of the cave besides the materials poured into it
+// edge fills the column on either side of the grid. A unit that moves into it has left the cave
+// trail marks the locations the falling unit has passed through so it never moves back into them
+const (
+	air   byte = '.'
+	rock  byte = '#'
+	edge  byte = '|'
+	trail byte = '~'
+)
+
+// Cave represents the 2D grid of objects in the cave
+// cells is a dense grid of width * height locations. The location x,y is stored at cells[y*width+x-minX]
+// The first and last column of the grid are edge. Between them the grid covers every location sand can reach
+// which is worked out from the rocks and the source
+// maxY is the furthest rock from the top. Without a floor anything below this point is the "void"
+// With a floor it is an infinite line of rock at floorY which is checked without being stored in cells
+type Cave struct {
+	cells         []byte
+	minX          int
+	width, height int
+	maxY          int
+	hasFloor      bool
+	floorY        int
+	source        Pair
+}
+
+// parseRocks reads each line of the input as a path of rock such as "498,4 -> 498,6 -> 496,6"
+// Every path is returned as the list of corners the rock passes through
+func parseRocks(r io.Reader) ([][]Pair, error) {
+	var paths [][]Pair
+
+	fileScanner := bufio.NewScanner(r)
+	line := 0
+	for fileScanner.Scan() {
+		line++
+		if fileScanner.Text() == "" {
+			continue
+		}
+
+		var path []Pair
+		for _, corner := range strings.Split(fileScanner.Text(), " -> ") {
+			x, y, ok := strings.Cut(corner, ",")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected x,y but found %q", line, corner)
+			}
+
+			var p Pair
+			var err error
+			if p.x, err = strconv.Atoi(x); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if p.y, err = strconv.Atoi(y); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if p.y < 0 {
+				return nil, fmt.Errorf("line %d: rock at %d,%d is above the top of the cave", line, p.x, p.y)
+			}
+
+			// Rock paths are made of horizontal and vertical lines only
+			if len(path) > 0 {
+				previous := path[len(path)-1]
+				if previous.x != p.x && previous.y != p.y {
+					return nil, fmt.Errorf("line %d: rock from %d,%d to %d,%d is not a straight line", line, previous.x, previous.y, p.x, p.y)
+				}
+			}
+
+			path = append(path, p)
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, fileScanner.Err()
+}
+
+// newCave creates a cave holding the rock paths with sand poured in from source
+// If hasFloor is true there is an infinite floor two below the lowest rock
+func newCave(paths [][]Pair, source Pair, hasFloor bool) *Cave {
+	c := &Cave{source: source, hasFloor: hasFloor}
+
+	// Find the bounding box of every corner of the rock paths and the source
+	// Every rock lies on a line between two corners so the corners alone give the bounds
+	minX, maxX := source.x, source.x
+	c.maxY = source.y
+	for _, path := range paths {
+		for _, p := range path {
+			if p.x < minX {
+				minX = p.x
+			}
+			if p.x > maxX {
+				maxX = p.x
+			}
+			if p.y > c.maxY {
+				c.maxY = p.y
+			}
+		}
+	}
+
+	if hasFloor {
+		// Sand can only spread one column to each side for every row it falls
+		// It can never get further from the source than the distance from the source to the floor
+		c.floorY = c.maxY + 2
+		spread := c.floorY - source.y
+		if source.x-spread < minX {
+			minX = source.x - spread
+		}
+		if source.x+spread > maxX {
+			maxX = source.x + spread
+		}
+		c.height = c.floorY
+	} else {
+		c.height = c.maxY + 1
+	}
+
+	// Add an edge column on either side
+	c.minX = minX - 1
+	c.width = maxX - minX + 3
+	c.cells = []byte(strings.Repeat(string(air), c.width*c.height))
+	for y := 0; y < c.height; y++ {
+		c.cells[y*c.width] = edge
+		c.cells[y*c.width+c.width-1] = edge
+	}
+
+	// Add all sections of rock between each pair of corners
+	for _, path := range paths {
+		for i := 0; i+1 < len(path); i++ {
+			from, to := path[i], path[i+1]
+			step := Pair{sign(to.x - from.x), sign(to.y - from.y)}
+			for p := from; ; p = (Pair{p.x + step.x, p.y + step.y}) {
+				c.set(p, rock)
+				if p == to {
+					break
+				}
+			}
+		}
+		// A path of a single corner is a single rock
+		if len(path) == 1 {
+			c.set(path[0], rock)
+		}
+	}
+
+	return c
+}
+
+// sign returns -1, 0 or 1 for negative, zero or positive n
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// set places item at location p which must be inside the grid
+func (c *Cave) set(p Pair, item byte) {
+	c.cells[p.y*c.width+p.x-c.minX] = item
+}
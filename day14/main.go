@@ -1,239 +1,134 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
-	"strings"
 )
 
-type Pair struct {
-	x, y int
-}
+// render prints the cave as text after every render units of sand when it is above zero
+var render = flag.Int("render", 0, "print the cave after every N units of sand")
+
+// gifPrefix is the start of the file names for animated GIFs of each part. No GIFs are written when it is empty
+var gifPrefix = flag.String("gif", "", "write animated GIFs to <prefix>-abyss.gif and <prefix>-floor.gif")
+
+// gifEvery is the number of units of sand between frames of the GIFs
+var gifEvery = flag.Int("gif-every", 250, "units of sand between GIF frames")
+
+// gifScale is the size in pixels of each location in the GIFs
+var gifScale = flag.Int("gif-scale", 2, "pixels per location in GIF frames")
+
+// material is the name of the material poured into the cave
+var material = flag.String("material", "sand", "material to pour: sand, water or gravel")
+
+// source is where the sand pours into the cave from
+var source = Pair{500, 0}
 
 func partOne() (int, error) {
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
+	paths, err := parseInput()
 	if err != nil {
 		return -1, err
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	// cave represents the 2D grid of objects in the cave
-	// rock is "#" , air is "." , and sand will be "o"
-	cave := make(map[Pair]rune)
-
-	// maxY tracks the furhest rock from the top. Anything below this point is the "void"
-	var maxY int
-
-	// Generate the cave structure
-	for fileScanner.Scan() {
-		location := strings.Split(fileScanner.Text(), " -> ")
-		for i := range location[:len(location)-1] {
-			// Get each Pair of x,y for the origin and end location of the rock
-			locationFrom := strings.Split(location[i], ",")
-			locationTo := strings.Split(location[i+1], ",")
-
-			// Get the x and y values for the start and end of the rock
-			fromX, _ := strconv.Atoi(locationFrom[0])
-			fromY, _ := strconv.Atoi(locationFrom[1])
-
-			toX, _ := strconv.Atoi(locationTo[0])
-			toY, _ := strconv.Atoi(locationTo[1])
-
-			// Add the rock sections in the cave
-			cave[Pair{toX, toY}] = '#'
-			cave[Pair{fromX, fromY}] = '#'
-
-			// Add all sections of rock between the start and end either in either a horizontal or vertical
-			for fromX != toX || fromY != toY {
-				cave[Pair{fromX, fromY}] = '#'
-				switch {
-				case fromX < toX:
-					// The rock formation goes from left to right
-					fromX++
-				case fromY < toY:
-					// The rock formation goes from top to bottom
-					fromY++
-				case fromX > toX:
-					// The rock formation goes from right to left
-					fromX--
-				case fromY > toY:
-					// The rock formation goes from bottom to top
-					fromY--
-				}
-			}
-
-			// Update the furthest furthest from the top to define the new edge of the "void"
-			if fromY >= maxY {
-				maxY = toY
-			}
-
-			if toY >= maxY {
-				maxY = toY
-			}
-		}
-	}
 
-	voidReached := false // track if a unit of sand has fallen to the abyss
-	sand := 0            // count the units of sand
-
-	for !voidReached {
-		newSand := Pair{500, 0}
-		for {
-
-			// If the sand has fallen past the last rock it will fall into the void
-			// all other sand will continue into the void
-			if newSand.y+1 > maxY {
-				voidReached = true
-				break
-			}
-			// directions is a slice of Pair that correspond to the 3 directions sand can fall. [0] = down, [1] = diagonal left, [2] = diagonal right
-			directions := []Pair{{newSand.x, newSand.y + 1}, {newSand.x - 1, newSand.y + 1}, {newSand.x + 1, newSand.y + 1}}
-
-			if cave[directions[0]] < '#' { // attempt to move straight down
-				newSand.y++
-			} else if cave[directions[1]] < '#' { // attempt to move SW
-				newSand.x--
-				newSand.y++
-			} else if cave[directions[2]] < '#' { // attempt to move SE
-				newSand.y++
-				newSand.x++
-			} else { // sand cannot move to falls into its final place
-				cave[newSand] = 'o'
-				sand++
-				break
-			}
-		}
-	}
+	// Without a floor sand eventually falls past the lowest rock into the void
+	cave := newCave(paths, source, false)
 
-	return sand, nil
+	return pour(cave, "abyss")
 }
 
 func partTwo() (int, error) {
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
+	paths, err := parseInput()
 	if err != nil {
 		return -1, err
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	// cave represents the 2D grid of objects in the cave
-	// rock is "#" , air is "." , and sand will be "o"
-	cave := make(map[Pair]rune)
-
-	// maxY tracks the furhest rock from the top. Anything below this point is the "void"
-	var maxY int
-
-	// track the maximum and minimum values of the floor so it can be filled with rocks
-	var floorStart, floorEnd int
-
-	// Generate the cave structure
-	for fileScanner.Scan() {
-		location := strings.Split(fileScanner.Text(), " -> ")
-		for i := range location[:len(location)-1] {
-			// Get each Pair of x,y for the origin and end location of the rock
-			locationFrom := strings.Split(location[i], ",")
-			locationTo := strings.Split(location[i+1], ",")
-
-			// Get the x and y values for the start and end of the rock
-			fromX, _ := strconv.Atoi(locationFrom[0])
-			fromY, _ := strconv.Atoi(locationFrom[1])
-
-			toX, _ := strconv.Atoi(locationTo[0])
-			toY, _ := strconv.Atoi(locationTo[1])
-
-			// Add the rock sections in the cave
-			cave[Pair{toX, toY}] = '#'
-			cave[Pair{fromX, fromY}] = '#'
-
-			// Add all sections of rock between the start and end either in either a horizontal or vertical
-			for fromX != toX || fromY != toY {
-				cave[Pair{fromX, fromY}] = '#'
-				switch {
-				case fromX < toX:
-					// The rock formation goes from left to right
-					fromX++
-				case fromY < toY:
-					// The rock formation goes from top to bottom
-					fromY++
-				case fromX > toX:
-					// The rock formation goes from right to left
-					fromX--
-				case fromY > toY:
-					// The rock formation goes from bottom to top
-					fromY--
-				}
-			}
-
-			// Update the furthest furthest from the top to define the new edge of the "void"
-			if fromY >= maxY {
-				maxY = toY
-			}
-			if toY >= maxY {
-				maxY = toY
-			}
-
-			// Update the width of the floor
-			if toX > floorEnd {
-				floorEnd = toX
-			}
-			if toX < floorStart {
-				floorStart = toX
-			}
-			if fromX > floorEnd {
-				floorEnd = fromX
-			}
-			if fromX < floorStart {
-				floorStart = fromX
-			}
-		}
+
+	// With a floor sand piles up until it blocks the source
+	cave := newCave(paths, source, true)
+
+	return pour(cave, "floor")
+}
+
+// pour fills the cave with the material from the flags and returns how many units came to rest
+// The cave is printed and animated as it fills depending on the flags. name is used for the GIF file name
+func pour(cave *Cave, name string) (int, error) {
+	m, ok := materials[*material]
+	if !ok {
+		return -1, fmt.Errorf("unknown material %q", *material)
+	}
+	if *render < 0 {
+		return -1, fmt.Errorf("render must be 0 or more, got %d", *render)
+	}
+	if *gifEvery < 1 {
+		return -1, fmt.Errorf("gif-every must be 1 or more, got %d", *gifEvery)
+	}
+	if *gifScale < 1 {
+		return -1, fmt.Errorf("gif-scale must be 1 or more, got %d", *gifScale)
+	}
+
+	if *render <= 0 && *gifPrefix == "" {
+		return cave.Pour(m), nil
 	}
 
-	// fill in the entire floor with rocks.
-	// use the maxY value as extra padding to imitate the floor being "infinite"
-	for i := floorStart - maxY; i < floorEnd+maxY; i++ {
-		cave[Pair{i, maxY + 2}] = '#'
+	var animation *Animation
+	if *gifPrefix != "" {
+		animation = NewAnimation(*gifScale, 5)
 	}
 
-	sand := 0 // count the units of sand
+	// Frames are taken every gcd of both intervals so each output can pick the ones it needs
+	every := gcd(*render, *gifEvery)
+	if animation == nil {
+		every = *render
+	}
 
-	for {
-		newSand := Pair{500, 0}
+	units := cave.PourWatch(m, every, func(count int, grain Pair, done bool) {
+		if *render > 0 && (count%*render == 0 || done) {
+			fmt.Printf("%d units of %s\n%s\n", count, m.Name, cave.Render(grain))
+		}
+		if animation != nil && (count%*gifEvery == 0 || done) {
+			animation.AddFrame(cave, grain)
+		}
+	})
 
-		// If the start point has been marked as sand it is blocked
-		if cave[newSand] == 'o' {
-			break
+	if animation != nil {
+		file, err := os.Create(fmt.Sprintf("%s-%s.gif", *gifPrefix, name))
+		if err != nil {
+			return -1, err
 		}
+		defer file.Close()
 
-		for {
-			// directions is a slice of Pair that correspond to the 3 directions sand can fall. [0] = down, [1] = diagonal left, [2] = diagonal right
-			directions := []Pair{{newSand.x, newSand.y + 1}, {newSand.x - 1, newSand.y + 1}, {newSand.x + 1, newSand.y + 1}}
-
-			if cave[directions[0]] < '#' { // attempt to move straight down
-				newSand.y++
-			} else if cave[directions[1]] < '#' { // attempt to move SW
-				newSand.x--
-				newSand.y++
-			} else if cave[directions[2]] < '#' { // attempt to move SE
-				newSand.y++
-				newSand.x++
-			} else { // sand cannot move to falls into its final place
-				cave[newSand] = 'o'
-				sand++
-				break
-			}
+		if err := animation.Encode(file); err != nil {
+			return -1, err
 		}
 	}
 
-	return sand, nil
+	return units, nil
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// parseInput reads the rock paths from the input file
+func parseInput() ([][]Pair, error) {
+	file, err := os.Open("./input.txt")
+	defer file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRocks(file)
 }
 
 func main() {
+	flag.Parse()
+
 	sand, err := partOne()
 	if err != nil {
 		log.Fatal(err)
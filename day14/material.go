@@ -0,0 +1,146 @@
+package main
+
+// Material is something that can be poured into the cave
+// Symbol is how it is drawn and stored in the cave once a unit comes to rest
+// Moves are tried in order every step. A unit moves to the first location that is air and comes to rest when none are
+type Material struct {
+	Name   string
+	Symbol byte
+	Moves  []Pair
+}
+
+// The built in materials
+var (
+	// Sand falls down and then diagonally left or right
+	Sand = Material{Name: "sand", Symbol: 'o', Moves: []Pair{{0, 1}, {-1, 1}, {1, 1}}}
+	// Water falls down and then spreads sideways left or right
+	Water = Material{Name: "water", Symbol: 'w', Moves: []Pair{{0, 1}, {-1, 0}, {1, 0}}}
+	// Gravel only falls straight down
+	Gravel = Material{Name: "gravel", Symbol: '*', Moves: []Pair{{0, 1}}}
+)
+
+// materials holds the built in materials by name
+var materials = map[string]Material{
+	Sand.Name:   Sand,
+	Water.Name:  Water,
+	Gravel.Name: Gravel,
+}
+
+// Pour drops units of material m from the source until no more can come to rest and returns how many did
+// Without a floor pouring stops once a unit falls past the lowest rock into the void
+// Pouring also stops when a unit moves into the edge of the cave as it would then fall forever
+// Otherwise pouring stops once a unit comes to rest at the source and blocks it
+func (c *Cave) Pour(m Material) int {
+	return c.PourWatch(m, 0, nil)
+}
+
+// noGrain is passed to a watch function when no unit is falling
+var noGrain = Pair{0, -1}
+
+// PourWatch pours material m the same way as Pour and calls watch after every few units come to rest
+// watch is called with the number of units at rest and the location of the unit that is falling or noGrain if there isn't one
+// It is called once every every units and once more with done set when pouring stops. If every is zero it is only called at the end
+//
+// Every unit follows the same path as the unit before it until the point where that unit came to rest
+// path holds the locations the last unit passed through so the next unit resumes from the location before the last one came to rest
+// Locations are kept as indexes into cells so each move is a single addition
+// Materials that move sideways mark their path with trail so they can't move back and forth forever
+func (c *Cave) PourWatch(m Material, every int, watch func(count int, falling Pair, done bool)) int {
+	// The index offsets of each move and whether that move goes down a row
+	moves := make([]int, len(m.Moves))
+	down := make([]bool, len(m.Moves))
+	sideways := false
+	for i, d := range m.Moves {
+		moves[i] = d.y*c.width + d.x
+		down[i] = d.y > 0
+		sideways = sideways || d.y == 0
+	}
+
+	// Any location at or past lastRow is on the bottom row of the grid
+	// Below it is either the floor or the void
+	lastRow := (c.height - 1) * c.width
+
+	// stop ends pouring and tells the watch function where the last unit was
+	// Any trail left by the last unit is cleared so the cave can be poured into again
+	count := 0
+	path := make([]int, 1, c.height+1)
+	stop := func(falling Pair) int {
+		for _, i := range path {
+			if c.cells[i] == trail {
+				c.cells[i] = air
+			}
+		}
+		if watch != nil {
+			watch(count, falling, true)
+		}
+		return count
+	}
+
+	// A unit that only falls moves down one row with every step so its path is never longer than the height of the grid
+	path[0] = c.source.y*c.width + c.source.x - c.minX
+	if c.cells[path[0]] != air {
+		return stop(noGrain)
+	}
+
+nextStep:
+	for len(path) > 0 {
+		current := path[len(path)-1]
+
+		// Above the bottom row every move stays inside the grid
+		if current < lastRow {
+			for _, move := range moves {
+				next := current + move
+				if cell := c.cells[next]; cell == air {
+					if sideways {
+						c.cells[current] = trail
+					}
+					path = append(path, next)
+					continue nextStep
+				} else if cell == edge {
+					// The unit has left the cave and will fall forever
+					return stop(c.location(current))
+				}
+			}
+		} else {
+			for i, move := range moves {
+				if down[i] {
+					// Below the bottom row the floor blocks the unit
+					if c.hasFloor {
+						continue
+					}
+					// Without a floor the unit falls past the last rock into the void
+					// all other units will follow it into the void
+					return stop(c.location(current))
+				}
+
+				next := current + move
+				if cell := c.cells[next]; cell == air {
+					if sideways {
+						c.cells[current] = trail
+					}
+					path = append(path, next)
+					continue nextStep
+				} else if cell == edge {
+					return stop(c.location(current))
+				}
+			}
+		}
+
+		// The unit cannot move so falls into its final place
+		// The next unit picks up from the location before this one
+		c.cells[current] = m.Symbol
+		count++
+		path = path[:len(path)-1]
+
+		if watch != nil && every > 0 && count%every == 0 && len(path) > 0 {
+			watch(count, c.location(path[len(path)-1]), false)
+		}
+	}
+
+	return stop(noGrain)
+}
+
+// location returns the x,y location of an index into cells
+func (c *Cave) location(index int) Pair {
+	return Pair{index%c.width + c.minX, index / c.width}
+}
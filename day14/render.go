@@ -0,0 +1,114 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"strings"
+)
+
+// falling is drawn for the unit of sand that is still falling
+const falling byte = '+'
+
+// Render returns the cave as text with rock "#", air "." and each material by its symbol
+// The unit falling at grain is drawn as "+" unless grain is noGrain
+// Materials that move sideways mark the path of the falling unit with "~" so it shows up too. Sand leaves no path
+// The edge columns are left out. With a floor the floor is drawn as the last row
+func (c *Cave) Render(grain Pair) string {
+	var b strings.Builder
+	for y := 0; y < c.height; y++ {
+		row := c.cells[y*c.width+1 : (y+1)*c.width-1]
+		if grain.y == y {
+			row = append([]byte(nil), row...)
+			row[grain.x-c.minX-1] = falling
+		}
+		b.Write(row)
+		b.WriteByte('\n')
+	}
+
+	if c.hasFloor {
+		b.WriteString(strings.Repeat(string(rock), c.width-2))
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// palette holds the colour of each item drawn in a GIF frame
+// The index of each colour is looked up through paletteIndex
+// Materials without their own colour are drawn the same as sand
+var palette = color.Palette{
+	color.RGBA{0x1b, 0x1b, 0x24, 0xff}, // air
+	color.RGBA{0x80, 0x80, 0x88, 0xff}, // rock
+	color.RGBA{0xe8, 0xc5, 0x6a, 0xff}, // sand
+	color.RGBA{0xe0, 0x40, 0x30, 0xff}, // falling
+	color.RGBA{0x3a, 0x7b, 0xd5, 0xff}, // water
+	color.RGBA{0x8a, 0x6e, 0x58, 0xff}, // gravel
+}
+
+var paletteIndex = map[byte]uint8{
+	air:           0,
+	trail:         0,
+	rock:          1,
+	Sand.Symbol:   2,
+	falling:       3,
+	Water.Symbol:  4,
+	Gravel.Symbol: 5,
+}
+
+// Animation collects frames of the cave and writes them as an animated GIF
+// Each location of the cave is drawn as a square of scale by scale pixels
+// delay is the time between frames in hundredths of a second
+type Animation struct {
+	scale int
+	delay int
+	anim  gif.GIF
+}
+
+// NewAnimation creates an empty animation
+func NewAnimation(scale, delay int) *Animation {
+	return &Animation{scale: scale, delay: delay}
+}
+
+// AddFrame draws the cave as it is now with the unit of sand at grain and adds it as the next frame
+func (a *Animation) AddFrame(c *Cave, grain Pair) {
+	height := c.height
+	if c.hasFloor {
+		height++
+	}
+
+	// The edge columns are left out of the image
+	width := c.width - 2
+
+	img := image.NewPaletted(image.Rect(0, 0, width*a.scale, height*a.scale), palette)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			item := rock
+			if y < c.height {
+				item = c.cells[y*c.width+x+1]
+			}
+			if grain.y == y && grain.x-c.minX-1 == x {
+				item = falling
+			}
+
+			index, ok := paletteIndex[item]
+			if !ok {
+				index = paletteIndex[Sand.Symbol]
+			}
+			for dy := 0; dy < a.scale; dy++ {
+				for dx := 0; dx < a.scale; dx++ {
+					img.SetColorIndex(x*a.scale+dx, y*a.scale+dy, index)
+				}
+			}
+		}
+	}
+
+	a.anim.Image = append(a.anim.Image, img)
+	a.anim.Delay = append(a.anim.Delay, a.delay)
+}
+
+// Encode writes the animation to w as a GIF
+func (a *Animation) Encode(w io.Writer) error {
+	return gif.EncodeAll(w, &a.anim)
+}
@@ -1,98 +1,130 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"math"
 	"os"
 )
 
-type Pair struct {
-	x, y int
-}
+// row is the y value checked in part one
+var row = flag.Int("row", 2000000, "row to count positions where a beacon cannot be in part one")
 
-func partOne() (int, error) {
+// boxMin and boxMax are the smallest and largest x and y values the distress beacon can have in part two
+var (
+	boxMin = flag.Int("min", 0, "smallest x and y of the distress beacon in part two")
+	boxMax = flag.Int("max", 4000000, "largest x and y of the distress beacon in part two")
+)
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
-	if err != nil {
-		return -1, err
-	}
-	fileScanner := bufio.NewScanner(file)
+// pngPath is the file the sensor field is drawn to. Nothing is drawn when it is empty
+var pngPath = flag.String("png", "", "draw the sensor field to this PNG file")
 
-	// line tracks all positions x = key where a beacon cannot exist
-	line := make(map[int]bool)
+// pngWidth is the width in pixels of the PNG. The field is scaled down to fit
+var pngWidth = flag.Int("png-width", 1000, "width in pixels of the PNG")
 
-	for fileScanner.Scan() {
-		// Read each sensor and its closest beacon
-		var sensor, beacon Pair
-		fmt.Sscanf(fileScanner.Text(), "Sensor at x=%d, y=%d: closest beacon is at x=%d, y=%d", &sensor.x, &sensor.y, &beacon.x, &beacon.y)
+// stats prints the coverage statistics of each sensor when set
+var stats = flag.Bool("stats", false, "print coverage statistics for each sensor")
 
-		// Calculate the manhattan distance between the sensor and beacon
-		distanceFromBeacon := int(math.Abs(float64(sensor.x)-float64(beacon.x))) + int(math.Abs(float64(sensor.y)-float64(beacon.y)))
+func partOne() (int, error) {
 
-		// Calculate how far the sensor is from the puzzle line 2000000.  | Y - Sy|
-		distanceFromLine := int(math.Abs(float64(2000000 - sensor.y)))
+	sensors, err := parseInput()
+	if err != nil {
+		return -1, err
+	}
 
-		// i represents all x values alone the horizontal line at Y = 2000000 where the diamond around sensor intersects
-		for i := 0; i <= distanceFromBeacon-distanceFromLine; i++ {
-			line[sensor.x+i] = true
-			line[sensor.x-i] = true
-		}
+	// Count every position on the row covered by a sensor
+	noBeacon := 0
+	for _, i := range Coverage(sensors, *row) {
+		noBeacon += i.Len()
+	}
 
-		// remove any beacons on the line from the count
-		if beacon.y == 2000000 {
-			delete(line, beacon.x)
+	// A beacon can be at the positions that already have one. Every beacon is covered by its sensor so remove each one on the row once
+	beacons := make(map[Pair]bool)
+	for _, s := range sensors {
+		if s.beacon.y == *row && !beacons[s.beacon] {
+			beacons[s.beacon] = true
+			noBeacon--
 		}
 	}
 
-	return len(line), nil
+	return noBeacon, nil
 }
 
 func partTwo() (int, error) {
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
+	sensors, err := parseInput()
 	if err != nil {
 		return -1, err
 	}
-	fileScanner := bufio.NewScanner(file)
 
-	// sensors hold coordinates for all sensors are the key and the manhattan distance as the value
-	sensors := map[Pair]int{}
+	// The distress beacon must have x and y coordinates that are between min and max
+	box := Box{Pair{*boxMin, *boxMin}, Pair{*boxMax, *boxMax}}
+
+	// Ask for two points so a second one shows the answer isn't unique
+	points := Uncovered(sensors, box, 2)
+	switch len(points) {
+	case 0:
+		return -1, errors.New("every point in the box is covered by a sensor")
+	case 1:
+		// The tuning frequency multiplies x by 4000000 and adds y
+		return points[0].x*4000000 + points[0].y, nil
+	}
+
+	return -1, fmt.Errorf("the distress beacon is not unique, found %d,%d and %d,%d", points[0].x, points[0].y, points[1].x, points[1].y)
+}
 
-	for fileScanner.Scan() {
-		// Read each sensor and its closest beacon
-		var sensor, beacon Pair
-		fmt.Sscanf(fileScanner.Text(), "Sensor at x=%d, y=%d: closest beacon is at x=%d, y=%d", &sensor.x, &sensor.y, &beacon.x, &beacon.y)
+// debug prints the sensor statistics and draws the sensor field depending on the flags
+func debug() error {
+	sensors, err := parseInput()
+	if err != nil {
+		return err
+	}
 
-		// Calculate the manhattan distance between the sensor and beacon
-		sensors[sensor] = int(math.Abs(float64(sensor.x)-float64(beacon.x))) + int(math.Abs(float64(sensor.y)-float64(beacon.y)))
+	if *stats {
+		if err := WriteStats(os.Stdout, Stats(sensors)); err != nil {
+			return err
+		}
 	}
 
-	// The distress beacon must have x and y coordinates that are between 0 and 4000000
-	for y := 0; y <= 4000000; y++ {
-	loop:
-		for x := 0; x <= 4000000; x++ {
-			// Check the current x,y position against each found sensor
-			for sensor, distance := range sensors {
-				// dx and yx represents the difference from the sensor x y and the current point x y
-				// If the distance from sensor to the current point is less than the distance from the sensor to the beacon then the current point is within the area covered by the the sensors diamond. No closer beacon is here
-				if dx, dy := sensor.x-x, sensor.y-y; math.Abs(float64(dx))+math.Abs(float64(dy)) <= float64(distance) {
-					// Increment x outside the current sensors diamond
-					x += int(float64(distance)-math.Abs(float64(dy))) + dx
-					continue loop
-				}
-			}
-			return x*4000000 + y, nil
+	if *pngPath != "" {
+		// Check the width before the file is created so a bad width doesn't leave an empty PNG behind
+		if *pngWidth < 1 {
+			return fmt.Errorf("png-width must be 1 or more, got %d", *pngWidth)
+		}
+
+		// Draw every gap in the box up to a limit so a search that isn't unique can still be seen
+		box := Box{Pair{*boxMin, *boxMin}, Pair{*boxMax, *boxMax}}
+		gaps := Uncovered(sensors, box, 100)
+
+		file, err := os.Create(*pngPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := Render(file, sensors, box, gaps, *pngWidth); err != nil {
+			return err
 		}
 	}
 
-	return -1, nil
+	return nil
+}
+
+// parseInput reads the sensors from the input file
+func parseInput() ([]Sensor, error) {
+	file, err := os.Open("./input.txt")
+	defer file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSensors(file)
 }
 
 func main() {
+	flag.Parse()
+
 	noBeacon, err := partOne()
 	if err != nil {
 		log.Fatal(err)
@@ -104,4 +136,8 @@ func main() {
 		log.Fatal(err)
 	}
 	fmt.Println(noBeacon)
+
+	if err := debug(); err != nil {
+		log.Fatal(err)
+	}
 }
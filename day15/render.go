@@ -0,0 +1,115 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Colours used when drawing the sensor field
+var (
+	backgroundColour = color.RGBA{0x10, 0x12, 0x1c, 0xff}
+	boxColour        = color.RGBA{0x70, 0x70, 0x78, 0xff}
+	sensorColour     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	beaconColour     = color.RGBA{0x40, 0xd0, 0x60, 0xff}
+	gapColour        = color.RGBA{0xff, 0x30, 0x30, 0xff}
+)
+
+// Render draws the sensor diamonds, beacons, search box and any gaps scaled down so the image is width pixels wide
+// The height is chosen to keep the same scale on both axes
+// Each pixel is coloured by how many sensors cover the position at its centre, brighter for more sensors
+func Render(w io.Writer, sensors []Sensor, box Box, gaps []Pair, width int) error {
+	// Find the bounds of every diamond and the box so everything fits in the image
+	bounds := box
+	for _, s := range sensors {
+		bounds.min.x = minOf(bounds.min.x, s.position.x-s.radius)
+		bounds.min.y = minOf(bounds.min.y, s.position.y-s.radius)
+		bounds.max.x = maxOf(bounds.max.x, s.position.x+s.radius)
+		bounds.max.y = maxOf(bounds.max.y, s.position.y+s.radius)
+	}
+
+	// scale is the number of positions across each pixel
+	spanX := bounds.max.x - bounds.min.x + 1
+	spanY := bounds.max.y - bounds.min.y + 1
+	scale := float64(spanX) / float64(width)
+	height := int(float64(spanY)/scale) + 1
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// toPixel converts a position to the pixel it falls in
+	toPixel := func(p Pair) (int, int) {
+		return int(float64(p.x-bounds.min.x) / scale), int(float64(p.y-bounds.min.y) / scale)
+	}
+
+	// Colour each pixel by the number of sensors covering its centre
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			centre := Pair{
+				bounds.min.x + int((float64(px)+0.5)*scale),
+				bounds.min.y + int((float64(py)+0.5)*scale),
+			}
+
+			count := 0
+			for _, s := range sensors {
+				if distance(s.position, centre) <= s.radius {
+					count++
+				}
+			}
+
+			img.Set(px, py, coverageColour(count))
+		}
+	}
+
+	// Outline the search box
+	left, top := toPixel(box.min)
+	right, bottom := toPixel(box.max)
+	for x := left; x <= right; x++ {
+		img.Set(x, top, boxColour)
+		img.Set(x, bottom, boxColour)
+	}
+	for y := top; y <= bottom; y++ {
+		img.Set(left, y, boxColour)
+		img.Set(right, y, boxColour)
+	}
+
+	// Mark the sensors and beacons with small squares and the gaps with a cross so they are visible at any scale
+	for _, s := range sensors {
+		x, y := toPixel(s.position)
+		drawSquare(img, x, y, 1, sensorColour)
+		x, y = toPixel(s.beacon)
+		drawSquare(img, x, y, 1, beaconColour)
+	}
+	for _, gap := range gaps {
+		x, y := toPixel(gap)
+		for d := -8; d <= 8; d++ {
+			img.Set(x+d, y, gapColour)
+			img.Set(x, y+d, gapColour)
+		}
+		drawSquare(img, x, y, 2, gapColour)
+	}
+
+	return png.Encode(w, img)
+}
+
+// coverageColour returns the colour of a pixel covered by count sensors
+func coverageColour(count int) color.Color {
+	if count == 0 {
+		return backgroundColour
+	}
+	// Each extra sensor makes the blue brighter up to a limit
+	level := 0x50 + 0x28*(count-1)
+	if level > 0xf0 {
+		level = 0xf0
+	}
+	return color.RGBA{0x20, uint8(level / 2), uint8(level), 0xff}
+}
+
+// drawSquare fills the square of pixels within size of x,y
+func drawSquare(img *image.RGBA, x, y, size int, c color.Color) {
+	for dy := -size; dy <= size; dy++ {
+		for dx := -size; dx <= size; dx++ {
+			img.Set(x+dx, y+dy, c)
+		}
+	}
+}
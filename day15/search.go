@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sort"
+)
+
+// Box is the area searched for the distress beacon from min to max including both
+type Box struct {
+	min, max Pair
+}
+
+// Uncovered returns the points inside box that are not within range of any sensor
+// At most limit points are returned. If limit is zero or less every point is returned
+//
+// The search works in rotated coordinates u = x + y and v = x - y where each sensor's diamond becomes a square
+// The lines just outside each square split the rotated plane into a grid of cells
+// Every cell is either completely inside or completely outside each square so a cell only needs checking once against each sensor
+// Points are then only listed for the cells that no sensor covers
+func Uncovered(sensors []Sensor, box Box, limit int) []Pair {
+	// The rotated box reaches from the top left to the bottom right corner in u
+	// and from the bottom left to the top right corner in v
+	boxU := Interval{box.min.x + box.min.y, box.max.x + box.max.y}
+	boxV := Interval{box.min.x - box.max.y, box.max.x - box.min.y}
+
+	// Every square starts at u - radius and the first line outside it is at u + radius + 1
+	// The same is true for v. Each cell runs from one line up to the one before the next
+	uLines := []int{boxU.start, boxU.end + 1}
+	vLines := []int{boxV.start, boxV.end + 1}
+	for _, s := range sensors {
+		u, v := s.position.x+s.position.y, s.position.x-s.position.y
+		uLines = append(uLines, u-s.radius, u+s.radius+1)
+		vLines = append(vLines, v-s.radius, v+s.radius+1)
+	}
+	uLines = uniqueSorted(uLines)
+	vLines = uniqueSorted(vLines)
+
+	var points []Pair
+	for i := 0; i+1 < len(uLines); i++ {
+		cellU := Interval{uLines[i], uLines[i+1] - 1}
+		if cellU.end < boxU.start || cellU.start > boxU.end {
+			continue
+		}
+
+		for j := 0; j+1 < len(vLines); j++ {
+			cellV := Interval{vLines[j], vLines[j+1] - 1}
+			if cellV.end < boxV.start || cellV.start > boxV.end {
+				continue
+			}
+
+			if covered(sensors, cellU, cellV) {
+				continue
+			}
+
+			points = box.pointsIn(cellU, cellV, points, limit)
+			if limit > 0 && len(points) >= limit {
+				return points
+			}
+		}
+	}
+
+	return points
+}
+
+// uniqueSorted sorts the values and removes any repeats
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// covered returns true if a single sensor covers the whole rotated cell
+func covered(sensors []Sensor, cellU, cellV Interval) bool {
+	for _, s := range sensors {
+		u, v := s.position.x+s.position.y, s.position.x-s.position.y
+		if u-s.radius <= cellU.start && cellU.end <= u+s.radius && v-s.radius <= cellV.start && cellV.end <= v+s.radius {
+			return true
+		}
+	}
+	return false
+}
+
+// pointsIn adds the points inside both the box and the rotated cell to points until there are limit of them
+// A rotated point u, v is only a real point when u and v are both even or both odd
+func (b Box) pointsIn(cellU, cellV Interval, points []Pair, limit int) []Pair {
+	// Only look at the values of u where the cell and the box overlap
+	// x = (u + v) / 2 and y = (u - v) / 2 must both be inside the box for some v in the cell
+	fromU := maxOf(cellU.start, cellV.start+2*b.min.y, 2*b.min.x-cellV.end, b.min.x+b.min.y)
+	toU := minOf(cellU.end, 2*b.max.x-cellV.start, cellV.end+2*b.max.y, b.max.x+b.max.y)
+
+	for u := fromU; u <= toU; u++ {
+		// The values of v that keep x and y inside the box for this u
+		fromV := maxOf(cellV.start, 2*b.min.x-u, u-2*b.max.y)
+		toV := minOf(cellV.end, 2*b.max.x-u, u-2*b.min.y)
+
+		// Move to the first v with the same parity as u
+		if (u-fromV)%2 != 0 {
+			fromV++
+		}
+
+		for v := fromV; v <= toV; v += 2 {
+			points = append(points, Pair{(u + v) / 2, (u - v) / 2})
+			if limit > 0 && len(points) >= limit {
+				return points
+			}
+		}
+	}
+
+	return points
+}
+
+// minOf returns the smallest of the values
+func minOf(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+	return first
+}
+
+// maxOf returns the largest of the values
+func maxOf(first int, rest ...int) int {
+	for _, value := range rest {
+		if value > first {
+			first = value
+		}
+	}
+	return first
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Pair represents a pair of x and y coordinates
+type Pair struct {
+	x, y int
+}
+
+// Sensor is a sensor and the closest beacon to it
+// radius is the manhattan distance from the sensor to the beacon
+// No other beacon can be within radius of the sensor so the sensor covers a diamond of that radius
+type Sensor struct {
+	position Pair
+	beacon   Pair
+	radius   int
+}
+
+// Interval is a range of x values from start to end including both
+type Interval struct {
+	start, end int
+}
+
+// Len returns how many x values are in the interval
+func (i Interval) Len() int {
+	return i.end - i.start + 1
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// distance returns the manhattan distance between a and b
+func distance(a, b Pair) int {
+	return abs(a.x-b.x) + abs(a.y-b.y)
+}
+
+// parseSensors reads each line of the input as a sensor and its closest beacon
+func parseSensors(r io.Reader) ([]Sensor, error) {
+	var sensors []Sensor
+
+	fileScanner := bufio.NewScanner(r)
+	line := 0
+	for fileScanner.Scan() {
+		line++
+		if fileScanner.Text() == "" {
+			continue
+		}
+
+		// Read each sensor and its closest beacon
+		var s Sensor
+		if _, err := fmt.Sscanf(fileScanner.Text(), "Sensor at x=%d, y=%d: closest beacon is at x=%d, y=%d", &s.position.x, &s.position.y, &s.beacon.x, &s.beacon.y); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// Calculate the manhattan distance between the sensor and beacon
+		s.radius = distance(s.position, s.beacon)
+
+		sensors = append(sensors, s)
+	}
+
+	return sensors, fileScanner.Err()
+}
+
+// Coverage returns the x values on row y that are within range of any sensor
+// Each sensor's diamond crosses the row as a single interval that is narrower the further the row is from the sensor
+// The intervals are sorted and merged so none of the returned intervals overlap or touch
+func Coverage(sensors []Sensor, y int) []Interval {
+	var intervals []Interval
+	for _, s := range sensors {
+		// How far the diamond reaches to each side on this row
+		reach := s.radius - abs(y-s.position.y)
+		if reach < 0 {
+			continue
+		}
+		intervals = append(intervals, Interval{s.position.x - reach, s.position.x + reach})
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start < intervals[j].start
+	})
+
+	var merged []Interval
+	for _, i := range intervals {
+		// Join the interval onto the last one if they overlap or are next to each other
+		if last := len(merged) - 1; last >= 0 && i.start <= merged[last].end+1 {
+			if i.end > merged[last].end {
+				merged[last].end = i.end
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+
+	return merged
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// SensorStats describes how much of the map a single sensor covers
+// area is the number of positions within range of the sensor
+// overlap is how many of those positions are also within range of at least one other sensor
+// overlapping is the number of other sensors whose diamond touches this one
+type SensorStats struct {
+	sensor      Sensor
+	area        int
+	overlap     int
+	overlapping int
+}
+
+// Stats returns the coverage statistics of every sensor in the same order as sensors
+func Stats(sensors []Sensor) []SensorStats {
+	stats := make([]SensorStats, len(sensors))
+	for i, s := range sensors {
+		stats[i] = SensorStats{
+			sensor: s,
+			// A diamond of radius r has rows of 1, 3, 5 ... 2r+1 ... 5, 3, 1 positions
+			area: 2*s.radius*s.radius + 2*s.radius + 1,
+		}
+
+		// Only the other sensors that touch this one can share positions with it
+		var others []Sensor
+		for j, other := range sensors {
+			if j != i && distance(s.position, other.position) <= s.radius+other.radius {
+				others = append(others, other)
+			}
+		}
+		stats[i].overlapping = len(others)
+		stats[i].overlap = coveredWithin(s, others)
+	}
+	return stats
+}
+
+// coveredWithin counts the positions in range of sensor s that are also in range of any of the others
+// Like Uncovered it splits the rotated plane into cells along the lines just outside each diamond
+// so every cell inside the diamond of s is either covered by the others or not
+func coveredWithin(s Sensor, others []Sensor) int {
+	u, v := s.position.x+s.position.y, s.position.x-s.position.y
+	squareU := Interval{u - s.radius, u + s.radius}
+	squareV := Interval{v - s.radius, v + s.radius}
+
+	uLines := []int{squareU.start, squareU.end + 1}
+	vLines := []int{squareV.start, squareV.end + 1}
+	for _, o := range others {
+		ou, ov := o.position.x+o.position.y, o.position.x-o.position.y
+		uLines = append(uLines, ou-o.radius, ou+o.radius+1)
+		vLines = append(vLines, ov-o.radius, ov+o.radius+1)
+	}
+	uLines = uniqueSorted(uLines)
+	vLines = uniqueSorted(vLines)
+
+	count := 0
+	for i := 0; i+1 < len(uLines); i++ {
+		cellU := Interval{uLines[i], uLines[i+1] - 1}
+		if cellU.start < squareU.start || cellU.end > squareU.end {
+			continue
+		}
+		for j := 0; j+1 < len(vLines); j++ {
+			cellV := Interval{vLines[j], vLines[j+1] - 1}
+			if cellV.start < squareV.start || cellV.end > squareV.end {
+				continue
+			}
+			if covered(others, cellU, cellV) {
+				count += rotatedPoints(cellU, cellV)
+			}
+		}
+	}
+	return count
+}
+
+// rotatedPoints counts the real points in a rotated cell
+// A rotated point u, v is only a real point when u and v are both even or both odd
+func rotatedPoints(cellU, cellV Interval) int {
+	evenU, oddU := parityCounts(cellU)
+	evenV, oddV := parityCounts(cellV)
+	return evenU*evenV + oddU*oddV
+}
+
+// parityCounts returns how many even and odd values are in the interval
+func parityCounts(i Interval) (even, odd int) {
+	even = i.Len() / 2
+	odd = i.Len() / 2
+	if i.Len()%2 != 0 {
+		// The extra value has the same parity as the start
+		if i.start%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	return even, odd
+}
+
+// WriteStats prints a table of the coverage statistics of each sensor to w
+func WriteStats(w io.Writer, stats []SensorStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "sensor\tposition\tbeacon\tradius\tarea\toverlap\tunique\toverlapping\t")
+	for i, s := range stats {
+		fmt.Fprintf(tw, "%d\t%d,%d\t%d,%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			i, s.sensor.position.x, s.sensor.position.y, s.sensor.beacon.x, s.sensor.beacon.y,
+			s.sensor.radius, s.area, s.overlap, s.area-s.overlap, s.overlapping)
+	}
+	return tw.Flush()
+}
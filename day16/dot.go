@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// routeColours are the colours used to highlight the route of each agent in turn
+var routeColours = []string{"red", "blue", "darkgreen", "darkorange", "purple", "brown"}
+
+// edge is an undirected edge between two valves with the valves in name order
+type edge struct {
+	from, to string
+}
+
+// newEdge returns the edge between a and b
+func newEdge(a, b string) edge {
+	if b < a {
+		a, b = b, a
+	}
+	return edge{a, b}
+}
+
+// WriteTunnelsDOT writes the tunnels between every valve as a Graphviz graph
+// Each valve is labelled with its flow rate and valves with flow are filled
+// The tunnels walked by each agent in plan are drawn in that agent's colour
+func WriteTunnelsDOT(w io.Writer, reachability map[string][]string, pressure map[string]int, plan Plan) error {
+	// Walk the shortest path between each pair of valves on every route
+	walked := make(map[edge][]string)
+	for i, agent := range plan.Agents {
+		colour := routeColours[i%len(routeColours)]
+		from := startValve
+		for _, v := range agent.Visits {
+			path := shortestPath(reachability, from, v.Valve)
+			for j := 1; j < len(path); j++ {
+				walked[newEdge(path[j-1], path[j])] = appendColour(walked[newEdge(path[j-1], path[j])], colour)
+			}
+			from = v.Valve
+		}
+	}
+
+	fmt.Fprintln(w, "graph tunnels {")
+	fmt.Fprintln(w, "\tnode [shape=circle fontsize=10];")
+	for _, valve := range sortedKeys(pressure) {
+		writeValve(w, valve, pressure[valve])
+	}
+
+	// Tunnels usually run both ways so each one is only drawn once
+	for _, valve := range sortedKeys(pressure) {
+		for _, next := range reachability[valve] {
+			if next < valve && contains(reachability[next], valve) {
+				continue
+			}
+			e := newEdge(valve, next)
+			fmt.Fprintf(w, "\t%s -- %s%s;\n", valve, next, edgeStyle(walked[e], ""))
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// WriteNetworkDOT writes the compressed network as a Graphviz graph
+// Every pair of valves is joined by an edge labelled with the minutes it takes to walk between them
+// The edges followed by each agent in plan are drawn in that agent's colour
+func WriteNetworkDOT(w io.Writer, n *Network, plan Plan) error {
+	walked := make(map[edge][]string)
+	for i, agent := range plan.Agents {
+		colour := routeColours[i%len(routeColours)]
+		from := startValve
+		for _, v := range agent.Visits {
+			walked[newEdge(from, v.Valve)] = appendColour(walked[newEdge(from, v.Valve)], colour)
+			from = v.Valve
+		}
+	}
+
+	fmt.Fprintln(w, "graph network {")
+	fmt.Fprintln(w, "\tnode [shape=circle fontsize=10];")
+	for i, valve := range n.names {
+		writeValve(w, valve, n.flow[i])
+	}
+	for i := range n.names {
+		for j := i + 1; j < len(n.names); j++ {
+			e := newEdge(n.names[i], n.names[j])
+			fmt.Fprintf(w, "\t%s -- %s%s;\n", e.from, e.to, edgeStyle(walked[e], fmt.Sprint(n.dist[i][j])))
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// writeValve writes the node of one valve labelled with its name and flow rate
+func writeValve(w io.Writer, valve string, flow int) {
+	style := ""
+	if flow > 0 {
+		style = " style=filled fillcolor=lightblue"
+	}
+	if valve == startValve {
+		style += " shape=doublecircle"
+	}
+	fmt.Fprintf(w, "\t%s [label=\"%s\\n%d\"%s];\n", valve, valve, flow, style)
+}
+
+// edgeStyle returns the attributes of an edge walked in colours with an optional label
+// Edges not walked by any agent are drawn light grey
+func edgeStyle(colours []string, label string) string {
+	var attrs []string
+	if label != "" {
+		attrs = append(attrs, fmt.Sprintf("label=%q", label))
+	}
+	if len(colours) == 0 {
+		attrs = append(attrs, "color=grey80")
+	} else {
+		// Graphviz draws an edge with a colour list as parallel lines of each colour
+		attrs = append(attrs, fmt.Sprintf("color=%q", strings.Join(colours, ":")), "penwidth=2")
+	}
+	return " [" + strings.Join(attrs, " ") + "]"
+}
+
+// shortestPath returns the valves on a shortest walk from one valve to another including both
+// Tunnels are tried in the order they are listed so the same path is found every time
+func shortestPath(reachability map[string][]string, from, to string) []string {
+	previous := map[string]string{from: ""}
+	toVisit := []string{from}
+	for len(toVisit) > 0 && toVisit[0] != to {
+		current := toVisit[0]
+		toVisit = toVisit[1:]
+
+		for _, next := range reachability[current] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				toVisit = append(toVisit, next)
+			}
+		}
+	}
+	if _, ok := previous[to]; !ok {
+		return nil
+	}
+
+	path := []string{to}
+	for valve := to; valve != from; {
+		valve = previous[valve]
+		path = append([]string{valve}, path...)
+	}
+	return path
+}
+
+// appendColour adds colour to colours unless it is already there
+func appendColour(colours []string, colour string) []string {
+	if contains(colours, colour) {
+		return colours
+	}
+	return append(colours, colour)
+}
+
+// contains returns true if s holds value
+func contains(s []string, value string) bool {
+	for _, v := range s {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
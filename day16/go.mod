@@ -1,8 +1,3 @@
 module github.com/CurtisVermeeren/advent-of-code-2022/day16
 
 go 1.18
-
-require (
-	github.com/RyanCarrier/dijkstra v1.1.0
-	github.com/ernestosuarez/itertools v0.0.0-20190516153236-40a02c159e7b
-)
@@ -2,145 +2,113 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"sort"
 	"strings"
-	"sync"
+)
+
+// startValve is the valve every agent starts at
+const startValve = "AA"
 
-	"github.com/RyanCarrier/dijkstra"
-	"github.com/ernestosuarez/itertools"
+// workers is the number of goroutines searching routes in part two
+var workers = flag.Int("workers", runtime.NumCPU(), "number of workers searching routes")
+
+// agents and minutes solve for a different number of agents and minutes instead of the puzzle when agents is above zero
+var (
+	agents  = flag.Int("agents", 0, "number of agents opening valves, 0 to solve both puzzle parts")
+	minutes = flag.Int("minutes", 30, "minutes the agents have when -agents is set")
 )
 
-func partOne(reachability map[string][]string, pressure map[string]int, mappingInt map[string]int, tunnels []string, matrix map[string]map[string]int) (int, error) {
-	maxPressure := 0
-	maxPressure = solveRecur(matrix, pressure, 0, 0, 0, "AA", tunnels, 30)
+// timeline prints the plan of every agent after each answer
+var timeline = flag.Bool("timeline", false, "print the plan and a timeline of every agent")
 
-	return maxPressure, nil
-}
+// dotPrefix writes the tunnels and the compressed network as Graphviz graphs with the route of part two or -agents highlighted
+var dotPrefix = flag.String("dot", "", "write Graphviz graphs to <prefix>-tunnels.dot and <prefix>-network.dot")
 
-func partTwo(reachability map[string][]string, pressure map[string]int, mappingInt map[string]int, tunnels []string, matrix map[string]map[string]int) (int, error) {
-
-	maxPressure := 0
-	mu := sync.Mutex{}
-
-	for i := 1; i < len(tunnels)/2; i++ {
-		// Generate all combinations of tunnel nodes of length i
-		for v := range itertools.CombinationsStr(tunnels, i) {
-			vcopy := []string{}
-			vcopy = append(vcopy, v...)
-
-			// For each combination of tunnels run two recursions. One on all combinations of tunnels in V and another on all combinations of tunnels not in v
-			go func() {
-				maxPressure2a := solveRecur(matrix, pressure, 0, 0, 0, "AA", vcopy, 26)
-				maxPressure2b := solveRecur(matrix, pressure, 0, 0, 0, "AA", createOpposite(tunnels, vcopy), 26)
-				mu.Lock()
-				if maxPressure2a+maxPressure2b > maxPressure {
-					maxPressure = maxPressure2a + maxPressure2b
-				}
-				mu.Unlock()
-			}()
-		}
-	}
-	return maxPressure, nil
+func partOne(ctx context.Context, network *Network, workers int) (Plan, error) {
+	return Solve(ctx, network, 1, 30, workers)
+}
 
+func partTwo(ctx context.Context, network *Network, workers int) (Plan, error) {
+	// Teaching the elephant takes 4 minutes but it then opens valves alongside you
+	return Solve(ctx, network, 2, 26, workers)
 }
 
-// calculateReachabilityMatrix returns a reachability matrix for the graph represented by reachability.
-// reachability maps graph nodes as a key to a slice of strings representing which nodes the key node can reach.
-// mappingInt represents a mode of nodes to weight values.
-// Returns a map of graph nodes which maps to a map of graph nodes reachable by the first node and an integer representing the shortest distance between the two nodes.
-func calculateReachabilityMatrix(reachability map[string][]string, mappingInt map[string]int) map[string]map[string]int {
+// parseValves reads the flow rate and tunnels of every valve
+// reachability maps each valve to the valves its tunnels lead to and pressure maps each valve to its flow rate
+func parseValves(r io.Reader) (reachability map[string][]string, pressure map[string]int, err error) {
+	reachability = map[string][]string{}
+	pressure = map[string]int{}
 
-	// Build a graph of all nodes using the dijkstra package
-	graph := dijkstra.NewGraph()
-	for k := range reachability {
-		graph.AddVertex(mappingInt[k])
-	}
-	for k, v := range reachability {
-		for _, l := range v {
-			graph.AddArc(mappingInt[k], mappingInt[l], 1)
-		}
-	}
+	fileScanner := bufio.NewScanner(r)
+	for line := 1; fileScanner.Scan(); line++ {
+		text := fileScanner.Text()
 
-	matrix := map[string]map[string]int{}
-	for k1, v1 := range mappingInt {
-		matrix[k1] = map[string]int{}
-		// Find the shortest distance between each set of nodes and store them in the reachability matrix.
-		for k2, v2 := range mappingInt {
-			best, _ := graph.Shortest(v1, v2)
-			matrix[k1][k2] = int(best.Distance)
+		// Each line is "Valve AA has flow rate=0; tunnels lead to valves DD, II, BB"
+		// A valve with a single tunnel reads "tunnel leads to valve DD" instead
+		var valve string
+		var rate int
+		_, err := fmt.Sscanf(text, "Valve %s has flow rate=%d;", &valve, &rate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid valve %q: %w", line, text, err)
 		}
-	}
-
-	return matrix
-}
-
-// matrix is the reachabilty matrix of all nodes
-// pressure is a map of nodes to their pressure values
-// currentTime is an integer representing the amount of time passed
-// currentPressure is an integer representing the current pressure being released
-// currentFlow is the current flow rate of all visited nodes added together
-// currentTunnel is the current node being visited in the recursion
-// remaining is a slice of all remaining nodes that haven't been visited
-// limit represents the time limit enforced by the challenge
-func solveRecur(matrix map[string]map[string]int, pressures map[string]int, currentTime int, currentPressure int, currentFlow int, currentTunnel string, remaining []string, limit int) int {
-
-	nScore := currentPressure + (limit-currentTime)*currentFlow
-	max := nScore
-
-	for _, v := range remaining {
-		// distance and open represents the time passed to move from currentTunnel to v and adding 1 for the time it takes to open the valve
-		distanceAndOpen := matrix[currentTunnel][v] + 1
-		newTime := currentTime + distanceAndOpen
-		// check that this value is reached and opened within the time limit
-		if newTime < limit {
-			// calculate the new pressure released for visiting this node. Take the current pressure and release the currentFlow rate for each minute is takes to reach node v
-			newPressure := currentPressure + distanceAndOpen*currentFlow
-			// newFlow is the sum of the current flow and the added from visitng node v
-			newFlow := currentFlow + pressures[v]
-			// recursively continue from this node and attempt to release more pressure
-			possibleScore := solveRecur(matrix, pressures, newTime, newPressure, newFlow, v, removeFromList(remaining, v), limit)
-			// Find the largest pressure that can be released
-			if possibleScore > max {
-				max = possibleScore
-			}
+		if _, ok := pressure[valve]; ok {
+			return nil, nil, fmt.Errorf("line %d: valve %s is listed twice", line, valve)
 		}
-	}
 
-	return max
-}
+		_, tunnels, _ := strings.Cut(text, "; ")
+		var paths []string
+		switch {
+		case strings.HasPrefix(tunnels, "tunnels lead to valves "):
+			paths = strings.Split(strings.TrimPrefix(tunnels, "tunnels lead to valves "), ", ")
+		case strings.HasPrefix(tunnels, "tunnel leads to valve "):
+			paths = []string{strings.TrimPrefix(tunnels, "tunnel leads to valve ")}
+		default:
+			return nil, nil, fmt.Errorf("line %d: invalid tunnels %q", line, tunnels)
+		}
 
-// createOpposite returns a slice which contains all the alaments from slice "all" that are not prestent in "partial"
-func createOpposite(all []string, partial []string) []string {
-	new := []string{}
+		reachability[valve] = paths
+		pressure[valve] = rate
+	}
+	if err := fileScanner.Err(); err != nil {
+		return nil, nil, err
+	}
 
-loop:
-	for _, v := range all {
-		for _, w := range partial {
-			if v == w {
-				continue loop
+	// Every tunnel must lead to a valve that was listed
+	for _, valve := range sortedKeys(pressure) {
+		for _, next := range reachability[valve] {
+			if _, ok := pressure[next]; !ok {
+				return nil, nil, fmt.Errorf("tunnel from valve %s leads to unknown valve %q", valve, next)
 			}
 		}
-
-		new = append(new, v)
 	}
-	return new
+
+	return reachability, pressure, nil
 }
 
-// removeFromList returns a slice of all values of slice in that are not equal to v
-// this removes v from the returned slice
-func removeFromList(in []string, v string) []string {
-	new := []string{}
-	for _, i := range in {
-		if i != v {
-			new = append(new, i)
-		}
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
-	return new
+	sort.Strings(keys)
+	return keys
 }
 
 func main() {
+	flag.Parse()
+
+	// Stop searching when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	// Read the input file
 	file, err := os.Open("./input.txt")
@@ -148,57 +116,77 @@ func main() {
 		log.Fatal(err)
 	}
 	defer file.Close()
-	fileScanner := bufio.NewScanner(file)
 
-	// Create reachability, pressure, and weight maps
-	reachability := map[string][]string{}
-	pressure := map[string]int{}
+	// Create reachability and pressure maps
+	reachability, pressure, err := parseValves(file)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	mappingInt := map[string]int{}
-	count := 0
+	// Compress the graph to the valves with flow and the distances between them
+	network, err := compress(reachability, pressure, startValve)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	for fileScanner.Scan() {
-		parts := strings.Split(fileScanner.Text(), " ")
-		valve := parts[1]
-		var rate int
-		_, err := fmt.Sscanf(parts[4], "rate=%d;", &rate)
+	if *agents > 0 {
+		plan, err := Solve(ctx, network, *agents, *minutes, *workers)
 		if err != nil {
 			log.Fatal(err)
 		}
-
-		paths := parts[9:]
-
-		reachability[valve] = []string{}
-		for _, v := range paths {
-			reachability[valve] = append(reachability[valve], strings.TrimSuffix(v, ","))
-		}
-		pressure[valve] = rate
-
-		mappingInt[valve] = count
-		count++
+		printPlan(plan)
+		writeDOT(reachability, pressure, network, plan)
+		return
 	}
 
-	// Build tunnels of only valves with a flow rate more than 0
-	// These are the only destination nodes worth visiting to decrease pressure
-	tunnels := []string{}
-	for k, v := range pressure {
-		if v != 0 {
-			tunnels = append(tunnels, k)
-		}
-	}
-
-	// Use Dijkstra to build a reachability matrix
-	matrix := calculateReachabilityMatrix(reachability, mappingInt)
-
-	pressureReleased, err := partOne(reachability, pressure, mappingInt, tunnels, matrix)
+	plan, err := partOne(ctx, network, *workers)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(pressureReleased)
+	printPlan(plan)
 
-	pressureReleased, err = partTwo(reachability, pressure, mappingInt, tunnels, matrix)
+	plan, err = partTwo(ctx, network, *workers)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(pressureReleased)
+	printPlan(plan)
+	writeDOT(reachability, pressure, network, plan)
+}
+
+// writeDOT writes both graphs with the route of plan highlighted when -dot is set
+func writeDOT(reachability map[string][]string, pressure map[string]int, network *Network, plan Plan) {
+	if *dotPrefix == "" {
+		return
+	}
+
+	graphs := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"tunnels", func(w io.Writer) error { return WriteTunnelsDOT(w, reachability, pressure, plan) }},
+		{"network", func(w io.Writer) error { return WriteNetworkDOT(w, network, plan) }},
+	}
+	for _, g := range graphs {
+		file, err := os.Create(*dotPrefix + "-" + g.name + ".dot")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := g.write(file); err != nil {
+			log.Fatal(err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// printPlan prints the pressure released by the plan and with -timeline the plan itself
+func printPlan(plan Plan) {
+	fmt.Println(plan.Pressure)
+	if *timeline {
+		if err := WritePlan(os.Stdout, plan); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println()
+	}
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// MaxValves is the most valves with a flow rate a network can have
+// The solvers build tables over every set of valves so each extra valve doubles their size
+// 20 valves takes around 20 seconds and 8 MB of table for each worker
+const MaxValves = 20
+
+// Network is the valve graph compressed down to the valves worth opening
+// Only valves with a flow rate above zero are kept. Each one is given an index so a set of valves is a bitmask
+// The start valve is kept as well at index start but never has a bit in a mask
+// dist holds the number of minutes to walk between every pair of kept valves
+type Network struct {
+	names []string
+	flow  []int
+	dist  [][]int
+	start int
+}
+
+// compress builds a Network from the tunnels and flow rates of every valve
+// The shortest distances are found with a breadth first search from each kept valve over the full tunnel graph
+func compress(reachability map[string][]string, pressure map[string]int, startValve string) (*Network, error) {
+	if _, ok := reachability[startValve]; !ok {
+		return nil, fmt.Errorf("start valve %s does not exist", startValve)
+	}
+
+	n := &Network{}
+
+	// Build tunnels of only valves with a flow rate more than 0
+	// These are the only destination nodes worth visiting to decrease pressure
+	// Sort by name so the indexes are the same every run
+	for _, valve := range sortedKeys(pressure) {
+		if pressure[valve] > 0 {
+			n.names = append(n.names, valve)
+			n.flow = append(n.flow, pressure[valve])
+		}
+	}
+	if len(n.names) > MaxValves {
+		return nil, fmt.Errorf("%d valves have a flow rate but at most %d are supported", len(n.names), MaxValves)
+	}
+
+	// The start valve goes after the valves with flow so it is never part of a mask
+	n.start = len(n.names)
+	n.names = append(n.names, startValve)
+	n.flow = append(n.flow, 0)
+
+	n.dist = make([][]int, len(n.names))
+	for i, valve := range n.names {
+		n.dist[i] = make([]int, len(n.names))
+
+		// distance maps every valve reached so far to the minutes it takes to walk there
+		distance := map[string]int{valve: 0}
+		toVisit := []string{valve}
+		for len(toVisit) > 0 {
+			current := toVisit[0]
+			toVisit = toVisit[1:]
+
+			for _, next := range reachability[current] {
+				if _, seen := distance[next]; !seen {
+					distance[next] = distance[current] + 1
+					toVisit = append(toVisit, next)
+				}
+			}
+		}
+
+		for j, other := range n.names {
+			d, ok := distance[other]
+			if !ok {
+				// Valves that can't be reached are treated as too far away to ever visit
+				d = 1 << 30
+			}
+			n.dist[i][j] = d
+		}
+	}
+
+	return n, nil
+}
+
+// full returns the mask with every valve worth opening set
+func (n *Network) full() uint32 {
+	return uint32(1)<<n.start - 1
+}
+
+// memoKey identifies one state of the search
+// valve is where the agent is, time is the minutes left and opened is the mask of valves that can no longer be opened
+type memoKey struct {
+	valve  int
+	time   int
+	opened uint32
+}
+
+// Solver finds the most pressure a single agent can release
+// memo holds the answer for every state already solved so that states reached by different routes are only solved once
+type Solver struct {
+	network *Network
+	memo    map[memoKey]int
+}
+
+// NewSolver creates a Solver for the network
+func NewSolver(n *Network) *Solver {
+	return &Solver{network: n, memo: make(map[memoKey]int)}
+}
+
+// Best returns the most pressure that can be released by an agent at valve with time minutes left
+// Valves set in opened are treated as already open so they are never visited
+// Pressure from a valve is counted in full when it is opened as it will release flow for every remaining minute
+func (s *Solver) Best(valve, time int, opened uint32) int {
+	key := memoKey{valve, time, opened}
+	if best, ok := s.memo[key]; ok {
+		return best
+	}
+
+	n := s.network
+	best := 0
+	for closed := n.full() &^ opened; closed != 0; closed &= closed - 1 {
+		next := bits.TrailingZeros32(closed)
+
+		// It takes the walking distance plus one minute to open the valve
+		remaining := time - n.dist[valve][next] - 1
+		if remaining <= 0 {
+			continue
+		}
+
+		pressure := n.flow[next]*remaining + s.Best(next, remaining, opened|1<<next)
+		if pressure > best {
+			best = pressure
+		}
+	}
+
+	s.memo[key] = best
+	return best
+}
+
+// Route returns the visits of a single agent releasing the most pressure in minutes when it may only open the valves in allowed
+// Each step follows a valve whose pressure plus the best of the rest matches the best from the current state
+func (s *Solver) Route(minutes int, allowed uint32) AgentPlan {
+	n := s.network
+	valve, time, opened := n.start, minutes, n.full()&^allowed
+
+	var plan AgentPlan
+	for best := s.Best(valve, time, opened); best > 0; best = s.Best(valve, time, opened) {
+		for closed := n.full() &^ opened; closed != 0; closed &= closed - 1 {
+			next := bits.TrailingZeros32(closed)
+
+			remaining := time - n.dist[valve][next] - 1
+			if remaining <= 0 {
+				continue
+			}
+
+			pressure := n.flow[next] * remaining
+			if pressure+s.Best(next, remaining, opened|1<<next) == best {
+				plan.Visits = append(plan.Visits, Visit{Valve: n.names[next], Arrive: minutes - remaining - 1, Pressure: pressure})
+				plan.Pressure += pressure
+				valve, time, opened = next, remaining, opened|1<<next
+				break
+			}
+		}
+	}
+	return plan
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"math/bits"
+	"sync"
+)
+
+// BestPerMask returns the most pressure a single agent can release in time minutes for every set of valves
+// The table is indexed by mask and holds the best pressure when only the valves in mask may be opened
+//
+// Every route from the start is walked once and the pressure it releases is recorded against the set of valves it opened
+// The routes are split between workers by the first valve visited. Each worker fills its own table and the tables are
+// merged once every worker is done so the result is the same however the work was scheduled
+// Every worker given a route holds a table of 1<<n.start ints so memory grows with both the valves and the workers
+// The search stops early with the context's error if ctx is cancelled
+func BestPerMask(ctx context.Context, n *Network, time, workers int) ([]int, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	firsts := make(chan int)
+	tables := make([][]int, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			walker := &routeWalker{network: n, done: ctx.Done()}
+			for first := range firsts {
+				remaining := time - n.dist[n.start][first] - 1
+				if remaining <= 0 {
+					continue
+				}
+
+				// The table is only made once the worker has a route to walk so idle workers cost nothing
+				if walker.best == nil {
+					walker.best = make([]int, 1<<n.start)
+				}
+				if !walker.walk(first, remaining, 1<<first, n.flow[first]*remaining) {
+					errs[w] = ctx.Err()
+					cancel()
+					// Keep receiving so the sender is never left blocked
+					for range firsts {
+					}
+					return
+				}
+			}
+			tables[w] = walker.best
+		}(w)
+	}
+
+	// Hand out the first valve of each route until every valve is taken or the search is cancelled
+send:
+	for first := 0; first < n.start; first++ {
+		select {
+		case firsts <- first:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(firsts)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Merge the tables of each worker keeping the best pressure for each set
+	// Workers that never walked a route have no table
+	best := make([]int, 1<<n.start)
+	for _, table := range tables {
+		for mask, pressure := range table {
+			if pressure > best[mask] {
+				best[mask] = pressure
+			}
+		}
+	}
+
+	// A route that opens fewer valves is also allowed for every larger set
+	// Carry the best of each set up to every set that includes it one valve at a time
+	for bit := 0; bit < n.start; bit++ {
+		for mask := range best {
+			if mask&(1<<bit) != 0 && best[mask^1<<bit] > best[mask] {
+				best[mask] = best[mask^1<<bit]
+			}
+		}
+	}
+
+	return best, nil
+}
+
+// routeWalker walks every route from one valve recording the best pressure for each set of opened valves
+// done is checked before each step so the walk stops soon after its context is cancelled
+type routeWalker struct {
+	network *Network
+	done    <-chan struct{}
+	best    []int
+}
+
+// walk continues the route at valve with time minutes left, the valves in opened already open and pressure released so far
+// It returns false if the walk was cancelled
+func (r *routeWalker) walk(valve, time int, opened uint32, pressure int) bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+	}
+
+	if pressure > r.best[opened] {
+		r.best[opened] = pressure
+	}
+
+	n := r.network
+	for closed := n.full() &^ opened; closed != 0; closed &= closed - 1 {
+		next := bits.TrailingZeros32(closed)
+
+		remaining := time - n.dist[valve][next] - 1
+		if remaining <= 0 {
+			continue
+		}
+
+		if !r.walk(next, remaining, opened|1<<next, pressure+n.flow[next]*remaining) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Visit is one valve opened by an agent
+// Arrive is the number of minutes passed when the agent reaches the valve. The valve is opened during the minute after
+// Pressure is the total pressure the valve releases from when it is opened until time runs out
+type Visit struct {
+	Valve    string
+	Arrive   int
+	Pressure int
+}
+
+// AgentPlan is the valves one agent opens in order and the pressure they release together
+type AgentPlan struct {
+	Visits   []Visit
+	Pressure int
+}
+
+// Plan is the best way for a number of agents to release pressure in the given minutes
+type Plan struct {
+	Minutes  int
+	Agents   []AgentPlan
+	Pressure int
+}
+
+// maxSplitValves is the most valves with a flow rate Solve accepts for three or more agents
+// Splitting the valves between more than two agents tries every part of every set which takes 3^n steps for n valves
+// 16 valves is around 43 million steps for each agent after the second
+const maxSplitValves = 16
+
+// Solve finds the most pressure agents working together can release in minutes and the plan each agent follows
+// The agents never open the same valve so the valves are split into one set for each agent
+//
+// The best pressure of a single agent for every set of valves is found first with BestPerMask
+// Then split[k][mask] is the best pressure k+1 agents can release sharing the valves in mask
+// built up from k agents by giving every possible part of mask to the new agent
+// The last agent only needs the split of every valve so one or two agents never walk the parts of every set
+func Solve(ctx context.Context, n *Network, agents, minutes, workers int) (Plan, error) {
+	if agents < 1 {
+		return Plan{}, fmt.Errorf("need at least one agent, got %d", agents)
+	}
+	if minutes < 0 {
+		return Plan{}, fmt.Errorf("minutes can't be negative, got %d", minutes)
+	}
+	if agents > 2 && n.start > maxSplitValves {
+		return Plan{}, fmt.Errorf("%d valves have a flow rate but at most %d are supported for more than two agents", n.start, maxSplitValves)
+	}
+
+	best, err := BestPerMask(ctx, n, minutes, workers)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	// split[k] and choice[k] are for k+1 agents. choice holds the part of mask given to the last agent added
+	split := [][]int{best}
+	choice := [][]uint32{nil}
+	for k := 1; k < agents-1; k++ {
+		split = append(split, make([]int, len(best)))
+		choice = append(choice, make([]uint32, len(best)))
+		for mask := range best {
+			if err := ctx.Err(); err != nil {
+				return Plan{}, err
+			}
+			split[k][mask], choice[k][mask] = bestSplit(best, split[k-1], uint32(mask))
+		}
+	}
+
+	// The last agent added shares every valve
+	full := n.full()
+	pressure, part := best[full], full
+	if agents > 1 {
+		pressure, part = bestSplit(best, split[agents-2], full)
+	}
+
+	// Take each agent's set back out of the split then find the route through it
+	solver := NewSolver(n)
+	plan := Plan{Minutes: minutes, Pressure: pressure}
+	mask := full
+	for k := agents - 1; k >= 0; k-- {
+		switch {
+		case k == 0:
+			part = mask
+		case k < agents-1:
+			part = choice[k][mask]
+		}
+		mask &^= part
+
+		agent := solver.Route(minutes, part)
+		if agent.Pressure != best[part] {
+			return Plan{}, fmt.Errorf("route for agent %d releases %d pressure but %d was expected", len(plan.Agents)+1, agent.Pressure, best[part])
+		}
+		plan.Agents = append(plan.Agents, agent)
+	}
+
+	return plan, nil
+}
+
+// bestSplit returns the best pressure from giving part of mask to one agent and the rest to the agents in previous
+// along with the part given to the one agent
+func bestSplit(best, previous []int, mask uint32) (int, uint32) {
+	pressure, chosen := -1, uint32(0)
+
+	// Walk every part of mask including the empty set
+	for part := mask; ; part = (part - 1) & mask {
+		if p := best[part] + previous[mask&^part]; p > pressure {
+			pressure, chosen = p, part
+		}
+		if part == 0 {
+			break
+		}
+	}
+	return pressure, chosen
+}
+
+// WritePlan prints the valves each agent opens then a timeline of what every agent does in each minute
+func WritePlan(w io.Writer, plan Plan) error {
+	for i, agent := range plan.Agents {
+		visits := make([]string, len(agent.Visits))
+		for j, v := range agent.Visits {
+			visits[j] = fmt.Sprintf("%s at %d (%d)", v.Valve, v.Arrive, v.Pressure)
+		}
+		if len(visits) == 0 {
+			visits = append(visits, "nothing")
+		}
+		fmt.Fprintf(w, "agent %d releases %d: %s\n", i+1, agent.Pressure, strings.Join(visits, ", "))
+	}
+	fmt.Fprintln(w)
+
+	// actions[i][m] is what agent i does during minute m+1
+	// opens[m] is the flow rate added by the valves opened during minute m+1
+	actions := make([][]string, len(plan.Agents))
+	opens := make([]int, plan.Minutes)
+	for i, agent := range plan.Agents {
+		actions[i] = make([]string, plan.Minutes)
+		for m := range actions[i] {
+			actions[i][m] = "-"
+		}
+
+		minute := 0
+		for _, v := range agent.Visits {
+			for ; minute < v.Arrive; minute++ {
+				actions[i][minute] = "move to " + v.Valve
+			}
+			actions[i][minute] = "open " + v.Valve
+			// A valve opened in the last minute would release nothing so is never visited
+			opens[minute] += v.Pressure / (plan.Minutes - v.Arrive - 1)
+			minute++
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "minute\t")
+	for i := range plan.Agents {
+		fmt.Fprintf(tw, "agent %d\t", i+1)
+	}
+	fmt.Fprintln(tw, "flow\treleased\t")
+
+	flow, released := 0, 0
+	for m := 0; m < plan.Minutes; m++ {
+		// Valves release pressure from the minute after they are opened
+		released += flow
+		fmt.Fprintf(tw, "%d\t", m+1)
+		for i := range plan.Agents {
+			fmt.Fprintf(tw, "%s\t", actions[i][m])
+		}
+		fmt.Fprintf(tw, "%d\t%d\t\n", flow, released)
+		flow += opens[m]
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxWidth is the widest chamber supported as every row is stored in a uint32
+const MaxWidth = 32
+
+// Each rock appears with its left edge spawnLeft from the left wall
+// and its bottom edge spawnGap rows above the highest rock or the floor
+const (
+	spawnLeft = 2
+	spawnGap  = 3
+)
+
+// pruneEvery is how many rows the chamber grows by between looking for rows that can be dropped
+const pruneEvery = 64
+
+// Jets is the repeating pattern of hot gas pushing falling rocks
+// Each push is -1 for left or 1 for right. next is the index of the next push
+type Jets struct {
+	pushes []int
+	next   int
+}
+
+// ParseJets reads the pattern of jets where "<" pushes left and ">" pushes right
+func ParseJets(pattern string) (*Jets, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("no jets found")
+	}
+
+	j := &Jets{pushes: make([]int, len(pattern))}
+	for i, c := range pattern {
+		switch c {
+		case '<':
+			j.pushes[i] = -1
+		case '>':
+			j.pushes[i] = 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d of jets", c, i)
+		}
+	}
+	return j, nil
+}
+
+// Push returns the direction of the next push and moves on to the one after wrapping back to the start
+func (j *Jets) Push() int {
+	push := j.pushes[j.next]
+	j.next = (j.next + 1) % len(j.pushes)
+	return push
+}
+
+// Chamber is the tall narrow chamber rocks fall into
+// rows holds one bitmask per row from the bottom up with bit x set when column x is filled
+// Rows no rock can reach are pruned from the bottom of rows. pruned is how many have been removed
+// so row y of the chamber is rows[y-pruned]. Anything below the rows kept is treated as solid
+// rows never has an empty row on top so the height of the tower is pruned + len(rows)
+type Chamber struct {
+	width   int
+	full    uint32
+	rows    []uint32
+	pruned  int
+	pruneAt int
+	keepAll bool
+}
+
+// NewChamber creates an empty chamber width columns wide
+func NewChamber(width int) (*Chamber, error) {
+	if width < 1 || width > MaxWidth {
+		return nil, fmt.Errorf("chamber width must be from 1 to %d, got %d", MaxWidth, width)
+	}
+	return &Chamber{width: width, full: uint32(1)<<width - 1, pruneAt: pruneEvery}, nil
+}
+
+// KeepRows stops the chamber pruning rows so the whole tower can still be drawn
+func (c *Chamber) KeepRows() {
+	c.keepAll = true
+}
+
+// Height returns the height of the tower of rocks
+func (c *Chamber) Height() int {
+	return c.pruned + len(c.rows)
+}
+
+// row returns the bitmask of row y counting from the bottom of the rows kept
+// Rows below the ones kept are solid and rows above the tower are empty
+func (c *Chamber) row(y int) uint32 {
+	if y < 0 {
+		return c.full
+	}
+	if y >= len(c.rows) {
+		return 0
+	}
+	return c.rows[y]
+}
+
+// fits returns true if rock can be placed with its bottom left corner at x, y without hitting a wall or other rock
+// y counts from the bottom of the rows kept
+func (c *Chamber) fits(rock Rock, x, y int) bool {
+	if x < 0 || x+rock.width > c.width {
+		return false
+	}
+	for i, r := range rock.rows {
+		if c.row(y+i)&(r<<x) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Drop lets rock fall into the chamber pushed by jets until it comes to rest
+// The rock is pushed by the next jet then falls one row, over and over until it can't fall any further
+func (c *Chamber) Drop(rock Rock, jets *Jets) error {
+	return c.DropWatch(rock, jets, nil)
+}
+
+// StepKind is one of the things that can happen to a falling rock
+type StepKind int
+
+// A rock appears, is pushed by a jet or falls one row
+const (
+	SpawnStep StepKind = iota
+	PushStep
+	FallStep
+)
+
+// Step describes the falling rock after something happened to it
+// Push is the direction of the jet for a PushStep. Moved is false if the jet or the fall was blocked
+// and a FallStep that did not move means the rock has come to rest
+// X, Y is the bottom left corner of the rock counting rows from the floor
+type Step struct {
+	Kind  StepKind
+	Push  int
+	Moved bool
+	X, Y  int
+}
+
+// DropWatch is Drop but calls watch after the rock appears, after every push and after every fall
+// The rock is only added to the chamber after the watch of the step where it comes to rest
+func (c *Chamber) DropWatch(rock Rock, jets *Jets, watch func(Step)) error {
+	if rock.width > c.width {
+		return fmt.Errorf("rock is %d wide but the chamber is only %d wide", rock.width, c.width)
+	}
+
+	x, y := spawnLeft, len(c.rows)+spawnGap
+	if x+rock.width > c.width {
+		// Narrow chambers start the rock against the right wall instead
+		x = c.width - rock.width
+	}
+	if watch != nil {
+		watch(Step{Kind: SpawnStep, Moved: true, X: x, Y: c.pruned + y})
+	}
+
+	for {
+		push := jets.Push()
+		moved := c.fits(rock, x+push, y)
+		if moved {
+			x += push
+		}
+		if watch != nil {
+			watch(Step{Kind: PushStep, Push: push, Moved: moved, X: x, Y: c.pruned + y})
+		}
+
+		moved = c.fits(rock, x, y-1)
+		if moved {
+			y--
+		}
+		if watch != nil {
+			watch(Step{Kind: FallStep, Moved: moved, X: x, Y: c.pruned + y})
+		}
+		if !moved {
+			break
+		}
+	}
+
+	// Add the rock to the rows it rests in
+	for i, r := range rock.rows {
+		for y+i >= len(c.rows) {
+			c.rows = append(c.rows, 0)
+		}
+		c.rows[y+i] |= r << x
+	}
+
+	if len(c.rows) >= c.pruneAt && !c.keepAll {
+		c.prune()
+		c.pruneAt = len(c.rows) + pruneEvery
+	}
+	return nil
+}
+
+// prune removes the rows at the bottom of the chamber that no falling rock can reach
+func (c *Chamber) prune() {
+	// Rows below the lowest reachable row are only ever checked to stop a rock falling
+	// Treating them as solid does not change where any rock comes to rest
+	if lowest := c.lowestReachable(); lowest > 0 {
+		c.rows = append([]uint32(nil), c.rows[lowest:]...)
+		c.pruned += lowest
+	}
+}
+
+// Surface returns the rows from the lowest row a falling rock can reach up to the top of the tower
+// Where every future rock comes to rest only depends on these rows so two chambers with the same surface
+// behave the same from then on apart from the height they start at
+func (c *Chamber) Surface() []uint32 {
+	return append([]uint32(nil), c.rows[c.lowestReachable():]...)
+}
+
+// lowestReachable returns the index in rows of the lowest row a falling rock can reach
+// Rocks only ever move left, right and down so every cell a rock passes through can be reached by a path of empty cells
+// moving in those directions from above the tower. The lowest row such a path reaches is the lowest row still needed
+func (c *Chamber) lowestReachable() int {
+	// reached marks the empty cells of a row that can be reached from above
+	reached := c.full
+	lowest := len(c.rows)
+
+	for y := len(c.rows); y > 0 && reached != 0; y-- {
+		// Cells can fall into the empty cells directly below them then spread sideways along the row
+		below := c.rows[y-1]
+		spread := reached &^ below
+		for {
+			next := (spread | spread<<1 | spread>>1) & c.full &^ below
+			if next == spread {
+				break
+			}
+			spread = next
+		}
+		reached = spread
+		if spread != 0 {
+			lowest = y - 1
+		}
+	}
+
+	return lowest
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Cycle is a repeating pattern in the tower found by dropping rocks
+// After Start rocks every Period rocks add HeightGain to the height of the tower
+// heights holds the height of the tower after each number of rocks from 0 up to at least Start + Period
+type Cycle struct {
+	Start      int
+	Period     int
+	HeightGain int
+	heights    []int
+}
+
+// cycleState is everything that decides how the tower grows from now on
+// rock is the index of the next rock, jet is the index of the next jet and surface is the encoded surface of the chamber
+type cycleState struct {
+	rock, jet int
+	surface   string
+}
+
+// FindCycle drops rocks until the chamber is in the same state twice then checks the pattern repeats for another period
+// The state is the next rock, the next jet and the surface of the tower so a repeat means the tower grows the same way again
+// An error is returned if no cycle is found after dropping limit rocks
+func FindCycle(s simulation, limit int) (Cycle, error) {
+	chamber, jets, err := s.start()
+	if err != nil {
+		return Cycle{}, err
+	}
+
+	// seen maps each state to the number of rocks dropped when it was first seen
+	seen := map[cycleState]int{}
+	heights := []int{0}
+
+	for i := 0; i < limit; i++ {
+		state := cycleState{i % len(s.rocks), jets.next, encodeSurface(chamber.Surface())}
+		if start, ok := seen[state]; ok {
+			c := Cycle{Start: start, Period: i - start, HeightGain: chamber.Height() - heights[start]}
+
+			// Drop another period of rocks checking each one grows the tower the same as one period before
+			for j := i; j < i+c.Period; j++ {
+				if err := chamber.Drop(s.rocks[j%len(s.rocks)], jets); err != nil {
+					return Cycle{}, err
+				}
+				heights = append(heights, chamber.Height())
+				if heights[j+1]-heights[j+1-c.Period] != c.HeightGain {
+					return Cycle{}, fmt.Errorf("cycle of %d rocks from rock %d did not repeat at rock %d", c.Period, start, j+1)
+				}
+			}
+			if end := (cycleState{(i + c.Period) % len(s.rocks), jets.next, encodeSurface(chamber.Surface())}); end != state {
+				return Cycle{}, fmt.Errorf("cycle of %d rocks from rock %d did not return to the same state", c.Period, start)
+			}
+
+			c.heights = heights
+			return c, nil
+		}
+		seen[state] = i
+
+		if err := chamber.Drop(s.rocks[i%len(s.rocks)], jets); err != nil {
+			return Cycle{}, err
+		}
+		heights = append(heights, chamber.Height())
+	}
+
+	return Cycle{}, fmt.Errorf("no cycle found in %d rocks", limit)
+}
+
+// HeightAfter returns the height of the tower after rocks have fallen
+// Counts up to the ones already dropped while finding the cycle are looked up directly
+// Beyond that the full periods are skipped and the rocks left over are looked up from the first period
+func (c Cycle) HeightAfter(rocks int) int {
+	if rocks < len(c.heights) {
+		return c.heights[rocks]
+	}
+	periods, remainder := (rocks-c.Start)/c.Period, (rocks-c.Start)%c.Period
+	return c.heights[c.Start+remainder] + periods*c.HeightGain
+}
+
+// encodeSurface packs the rows of a surface into a string so it can be part of a map key
+func encodeSurface(rows []uint32) string {
+	b := make([]byte, 4*len(rows))
+	for i, row := range rows {
+		binary.LittleEndian.PutUint32(b[4*i:], row)
+	}
+	return string(b)
+}
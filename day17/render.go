@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// How each cell of the chamber is drawn, matching the puzzle's illustrations
+const (
+	emptyCell   byte = '.'
+	restingRock byte = '#'
+	fallingRock byte = '@'
+)
+
+// Render returns the chamber as text from the top down with the floor as the last line
+// If rock is not nil it is drawn with symbol with its bottom left corner at x, y counting rows from the floor
+// Only the top rows rows are drawn unless rows is zero or less. The floor is left out if it is not one of them
+// Rows that have been pruned can't be drawn so the drawing stops at the lowest row kept
+func (c *Chamber) Render(rock *Rock, x, y int, symbol byte, rows int) string {
+	top := c.Height()
+	if rock != nil && y+rock.Height() > top {
+		top = y + rock.Height()
+	}
+	bottom := c.pruned
+	if rows > 0 && top-rows > bottom {
+		bottom = top - rows
+	}
+
+	var b strings.Builder
+	line := make([]byte, c.width+2)
+	line[0], line[c.width+1] = '|', '|'
+	for row := top - 1; row >= bottom; row-- {
+		mask := c.row(row - c.pruned)
+		for col := 0; col < c.width; col++ {
+			line[col+1] = emptyCell
+			if mask&(1<<col) != 0 {
+				line[col+1] = restingRock
+			}
+		}
+		if rock != nil && row >= y && row < y+rock.Height() {
+			r := rock.rows[row-y]
+			for col := 0; col < rock.width; col++ {
+				if r&(1<<col) != 0 {
+					line[x+col+1] = symbol
+				}
+			}
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	if bottom == 0 {
+		b.WriteString("+" + strings.Repeat("-", c.width) + "+\n")
+	}
+	return b.String()
+}
+
+// Replay drops rocks into a new chamber and writes a drawing of it to w after every step
+// With everyPush each rock is drawn when it appears, after every jet push and after every fall like the puzzle's example
+// Otherwise it is only drawn as each new rock appears. Only the top rows rows are drawn unless rows is zero or less
+func Replay(w io.Writer, s simulation, rocks int, everyPush bool, rows int) error {
+	chamber, jets, err := s.start()
+	if err != nil {
+		return err
+	}
+	chamber.KeepRows()
+
+	// Writes to bw keep the first error so it only needs checking once at the end
+	bw := bufio.NewWriter(w)
+	for i := 0; i < rocks; i++ {
+		rock := s.rocks[i%len(s.rocks)]
+		err := chamber.DropWatch(rock, jets, func(step Step) {
+			var caption string
+			symbol := fallingRock
+			switch {
+			case step.Kind == SpawnStep && i == 0:
+				caption = "The first rock begins falling:"
+			case step.Kind == SpawnStep:
+				caption = "A new rock begins falling:"
+			case !everyPush:
+				return
+			case step.Kind == PushStep:
+				direction := "right"
+				if step.Push < 0 {
+					direction = "left"
+				}
+				caption = "Jet of gas pushes rock " + direction + ":"
+				if !step.Moved {
+					caption = "Jet of gas pushes rock " + direction + ", but nothing happens:"
+				}
+			case step.Kind == FallStep && step.Moved:
+				caption = "Rock falls 1 unit:"
+			default:
+				caption = "Rock falls 1 unit, causing it to come to rest:"
+				symbol = restingRock
+			}
+
+			fmt.Fprintf(bw, "%s\n%s\n", caption, chamber.Render(&rock, step.X, step.Y, symbol, rows))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(bw, "The tower is %d units tall after %d rocks:\n%s", chamber.Height(), rocks, chamber.Render(nil, 0, 0, 0, rows))
+	return bw.Flush()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rock is the shape of a falling rock as one bitmask per row from the bottom row up
+// Bit x of a row is set when the rock fills column x counting from the left edge of the rock
+// width is the number of columns between the leftmost and rightmost filled column
+type Rock struct {
+	rows  []uint32
+	width int
+}
+
+// Height returns the number of rows the rock covers
+func (r Rock) Height() int {
+	return len(r.rows)
+}
+
+// DefaultRocks is the set of rocks from the puzzle in the order they fall
+var DefaultRocks = mustParseRocks(`####
+
+.#.
+###
+.#.
+
+..#
+..#
+###
+
+#
+#
+#
+#
+
+##
+##`)
+
+// ParseRock reads a picture of a rock drawn with "#" for rock and "." for air with the top row first
+// Every row must be the same length and the picture can't have empty rows or columns on its edges
+func ParseRock(picture string) (Rock, error) {
+	lines := strings.Split(strings.TrimSpace(picture), "\n")
+
+	var r Rock
+	r.width = len(lines[0])
+	if r.width == 0 {
+		return Rock{}, fmt.Errorf("rock is empty")
+	}
+	if r.width > MaxWidth {
+		return Rock{}, fmt.Errorf("rock is %d wide but at most %d is supported", r.width, MaxWidth)
+	}
+
+	var all uint32
+	r.rows = make([]uint32, len(lines))
+	for i, line := range lines {
+		if len(line) != r.width {
+			return Rock{}, fmt.Errorf("row %d of rock is %d wide but the first row is %d wide", i+1, len(line), r.width)
+		}
+
+		var row uint32
+		for x, c := range line {
+			switch c {
+			case '#':
+				row |= 1 << x
+			case '.':
+			default:
+				return Rock{}, fmt.Errorf("row %d of rock has unexpected %q", i+1, c)
+			}
+		}
+		if row == 0 {
+			return Rock{}, fmt.Errorf("row %d of rock is empty", i+1)
+		}
+
+		// The picture is drawn top down but rows are stored bottom up
+		r.rows[len(lines)-1-i] = row
+		all |= row
+	}
+
+	if all&1 == 0 || all&(1<<(r.width-1)) == 0 {
+		return Rock{}, fmt.Errorf("rock has an empty column on its edge")
+	}
+
+	return r, nil
+}
+
+// ParseRocks reads pictures of rocks separated by blank lines
+func ParseRocks(pictures string) ([]Rock, error) {
+	var rocks []Rock
+	for i, picture := range strings.Split(strings.TrimSpace(strings.ReplaceAll(pictures, "\r\n", "\n")), "\n\n") {
+		r, err := ParseRock(picture)
+		if err != nil {
+			return nil, fmt.Errorf("rock %d: %w", i+1, err)
+		}
+		rocks = append(rocks, r)
+	}
+	return rocks, nil
+}
+
+// mustParseRocks is ParseRocks for rocks built into the program which must always be valid
+func mustParseRocks(pictures string) []Rock {
+	rocks, err := ParseRocks(pictures)
+	if err != nil {
+		panic(err)
+	}
+	return rocks
+}
@@ -0,0 +1,3 @@
+module github.com/CurtisVermeeren/advent-of-code-2022/day18
+
+go 1.18
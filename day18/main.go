@@ -1,162 +1,121 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
-	"math"
 	"os"
+	"path/filepath"
+
+	"github.com/CurtisVermeeren/advent-of-code-2022/day18/voxel"
 )
 
-// Point represtents a 3d point
-type Point struct {
-	x, y, z int
-}
+// details prints the air pockets inside the droplet and the separate pieces of lava
+var details = flag.Bool("details", false, "print the air pockets and connected pieces of the droplet")
 
-// Add point p and q together
-func (p Point) Add(q Point) Point {
-	return Point{p.x + q.x, p.y + q.y, p.z + q.z}
-}
+// meshPath writes the droplet to a mesh file. The format is chosen by the extension which must be .obj or .stl
+var meshPath = flag.String("mesh", "", "write the droplet's exposed faces to a .obj or .stl file")
 
-func partOne() (int, error) {
+// meshFaces chooses which faces are written to the mesh
+var meshFaces = flag.String("mesh-faces", "exterior", "faces to write to the mesh: exterior or all")
 
-	file, err := os.Open("./input.txt")
-	defer file.Close()
-	if err != nil {
-		return -1, err
-	}
-	fileScanner := bufio.NewScanner(file)
-
-	// lava is a map of all points found from the input file.
-	// these are 3D points with x, y, z coordinates.
-	// min and max store the minimum and maximum x, y, z values found in the input to create a bounding box around the 3D shape
-	lava := map[Point]struct{}{}
-	min := Point{math.MaxInt, math.MaxInt, math.MaxInt}
-	max := Point{math.MinInt, math.MinInt, math.MinInt}
-
-	// Scan all lines of input. Each input line is a set of x, y, z coordinates indicating a block of "lava droplet"
-	// Check for minimum and maximum values of x, y, z
-	for fileScanner.Scan() {
-		var p Point
-		fmt.Sscanf(fileScanner.Text(), "%d,%d,%d", &p.x, &p.y, &p.z)
-		lava[p] = struct{}{}
-
-		min = Point{Min(min.x, p.x), Min(min.y, p.y), Min(min.z, p.z)}
-		max = Point{Max(max.x, p.x), Max(max.y, p.y), Max(max.z, p.z)}
-	}
+func partOne(lava *voxel.Grid) (int, error) {
+	// Every face of a cube that doesn't touch another cube of lava is exposed
+	return lava.SurfaceArea(), nil
+}
 
-	// Expand the bounding box by 1 on all sides
-	min = min.Add(Point{-1, -1, -1})
-	max = max.Add(Point{1, 1, 1})
+func partTwo(lava *voxel.Grid) (int, error) {
+	// Only the faces that can be reached by steam from outside the droplet count
+	return lava.ExteriorSurface(), nil
+}
 
-	// These delta points are the 6 locations that surround a cube
-	delta := []Point{
-		{-1, 0, 0}, {0, -1, 0}, {0, 0, -1},
-		{1, 0, 0}, {0, 1, 0}, {0, 0, 1},
-	}
+// printDetails prints the volume and surface of every air pocket and piece of lava
+func printDetails(lava *voxel.Grid) {
+	min, max := lava.Bounds()
+	fmt.Printf("%d cubes from %d,%d,%d to %d,%d,%d\n", lava.Len(), min.X, min.Y, min.Z, max.X, max.Y, max.Z)
 
-	// Track the total surface area of the droplet with surface
-	// For each cube of lava droplet we check all 6 locations next to it.
-	// If a location next to a lava droplet is found without another cube then we know this is an exposed face of the cube and we can increment the total surface area.
-	surface := 0
-	for p := range lava {
-		for _, d := range delta {
-			if _, ok := lava[p.Add(d)]; !ok {
-				surface++
-			}
-		}
+	pockets := lava.Pockets()
+	fmt.Printf("%d air pockets\n", len(pockets))
+	for _, p := range pockets {
+		first := p.Points[0]
+		fmt.Printf("  at %d,%d,%d volume %d surface %d\n", first.X, first.Y, first.Z, p.Volume(), p.Surface)
 	}
 
-	return surface, nil
+	components := lava.Components()
+	fmt.Printf("%d pieces of lava\n", len(components))
+	for _, c := range components {
+		first := c.Points[0]
+		fmt.Printf("  at %d,%d,%d volume %d surface %d\n", first.X, first.Y, first.Z, c.Volume(), c.Surface)
+	}
 }
 
-func partTwo() (int, error) {
-
-	file, err := os.Open("./input.txt")
-	defer file.Close()
-	if err != nil {
-		return -1, err
+// writeMesh writes the exposed faces of the droplet to path as OBJ or STL
+func writeMesh(lava *voxel.Grid, path string) error {
+	var exteriorOnly bool
+	switch *meshFaces {
+	case "exterior":
+		exteriorOnly = true
+	case "all":
+	default:
+		return fmt.Errorf("unknown mesh faces %q, expected exterior or all", *meshFaces)
 	}
-	fileScanner := bufio.NewScanner(file)
-
-	// The same as partOne
-	lava := map[Point]struct{}{}
-	min := Point{math.MaxInt, math.MaxInt, math.MaxInt}
-	max := Point{math.MinInt, math.MinInt, math.MinInt}
-
-	for fileScanner.Scan() {
-		var p Point
-		fmt.Sscanf(fileScanner.Text(), "%d,%d,%d", &p.x, &p.y, &p.z)
-		lava[p] = struct{}{}
-
-		min = Point{Min(min.x, p.x), Min(min.y, p.y), Min(min.z, p.z)}
-		max = Point{Max(max.x, p.x), Max(max.y, p.y), Max(max.z, p.z)}
+	faces := lava.Faces(exteriorOnly)
+
+	var write func(f *os.File) error
+	switch filepath.Ext(path) {
+	case ".obj":
+		write = func(f *os.File) error { return voxel.WriteOBJ(f, faces) }
+	case ".stl":
+		write = func(f *os.File) error { return voxel.WriteSTL(f, "droplet", faces) }
+	default:
+		return fmt.Errorf("unknown mesh format %q, expected .obj or .stl", filepath.Ext(path))
 	}
 
-	min = min.Add(Point{-1, -1, -1})
-	max = max.Add(Point{1, 1, 1})
-
-	delta := []Point{
-		{-1, 0, 0}, {0, -1, 0}, {0, 0, -1},
-		{1, 0, 0}, {0, 1, 0}, {0, 0, 1},
+	file, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-
-	// queue will be a slice of all Points that need to be visited in the search.
-	// We don't want to visit the same Point more than once so we track all Points that have been visited.
-	// We know min is outside the lava structure as we subtracted 1 from all its coordinates when creating the bounding box
-	queue := []Point{min}
-	visited := map[Point]struct{}{min: {}}
-
-	surface := 0
-
-	// Start at the minimum point
-	// For each point in the queue check all neighbours of the point for an adjacent lava block
-	// If am adjacent point is part of the lava defined by the input then increment the surface counter.
-	// If any point is not part of the lava, has not been visited already, and is within the bounds of the 3D grid it is added to the queue and marked as visited.
-	// Because we started with the min Point thats outside the lava cube structure we traverse through all empty non-lava cubes around the lava structure and increment the surface area when when a lava cube is reached.
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-		for _, d := range delta {
-			next := current.Add(d)
-			if _, ok := lava[next]; ok {
-				surface++
-			} else if _, ok := visited[next]; !ok && next.x >= min.x && next.x <= max.x && next.y >= min.y && next.y <= max.y && next.z >= min.z && next.z <= max.z {
-				visited[next] = struct{}{}
-				queue = append(queue, next)
-			}
-		}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
 	}
-
-	return surface, nil
+	return file.Close()
 }
 
-// Min returns the smaller value of a and b
-func Min(a, b int) int {
-	if a < b {
-		return a
+func main() {
+	flag.Parse()
+
+	file, err := os.Open("./input.txt")
+	if err != nil {
+		log.Fatal(err)
 	}
-	return b
-}
+	defer file.Close()
 
-// Max returns the larger value of a and b
-func Max(a, b int) int {
-	if a > b {
-		return a
+	// lava holds every cube of the droplet from the input file
+	lava, err := voxel.ParseGrid(file)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return b
-}
 
-func main() {
-	surface, err := partOne()
+	surface, err := partOne(lava)
 	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Println(surface)
 
-	surface, err = partTwo()
+	surface, err = partTwo(lava)
 	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Println(surface)
+
+	if *details {
+		printDetails(lava)
+	}
+
+	if *meshPath != "" {
+		if err := writeMesh(lava, *meshPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
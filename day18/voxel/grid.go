@@ -0,0 +1,138 @@
+// Package voxel stores shapes made of unit cubes in a dense 3D grid and measures their surfaces and the air inside them
+package voxel
+
+import "fmt"
+
+// Point represents a 3D point
+type Point struct {
+	X, Y, Z int
+}
+
+// Add point p and q together
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y, p.Z + q.Z}
+}
+
+// Neighbours are the 6 offsets from a cube to the cubes sharing one of its faces
+var Neighbours = [6]Point{
+	{-1, 0, 0}, {0, -1, 0}, {0, 0, -1},
+	{1, 0, 0}, {0, 1, 0}, {0, 0, 1},
+}
+
+// MaxCells is the largest number of cells a grid can hold
+const MaxCells = 1 << 30
+
+// Grid is a dense bitset of the cubes filled in a box
+// The box is the bounding box of the cubes grown by one cell on every side so the air around the shape is always connected
+// The cell at origin + x,y,z is bit i of bits where i = x + size.X*(y + size.Y*z)
+type Grid struct {
+	origin Point
+	size   Point
+	bits   []uint64
+	count  int
+}
+
+// NewGrid creates a grid holding every point as a filled cube
+// Points listed more than once are only counted once
+func NewGrid(points []Point) (*Grid, error) {
+	g := &Grid{size: Point{1, 1, 1}}
+	if len(points) > 0 {
+		min, max := points[0], points[0]
+		for _, p := range points {
+			min = Point{minInt(min.X, p.X), minInt(min.Y, p.Y), minInt(min.Z, p.Z)}
+			max = Point{maxInt(max.X, p.X), maxInt(max.Y, p.Y), maxInt(max.Z, p.Z)}
+		}
+
+		// Expand the bounding box by 1 on all sides
+		g.origin = min.Add(Point{-1, -1, -1})
+		g.size = Point{max.X - min.X + 3, max.Y - min.Y + 3, max.Z - min.Z + 3}
+		if g.size.X*g.size.Y > MaxCells/g.size.Z {
+			return nil, fmt.Errorf("grid of %d by %d by %d cells is larger than %d cells", g.size.X, g.size.Y, g.size.Z, MaxCells)
+		}
+	}
+
+	g.bits = make([]uint64, (g.cells()+63)/64)
+	for _, p := range points {
+		i, _ := g.index(p)
+		if g.bits[i/64]&(1<<(i%64)) == 0 {
+			g.bits[i/64] |= 1 << (i % 64)
+			g.count++
+		}
+	}
+	return g, nil
+}
+
+// Len returns the number of filled cubes
+func (g *Grid) Len() int {
+	return g.count
+}
+
+// Has returns true if the cube at p is filled. Every point outside the grid is empty
+func (g *Grid) Has(p Point) bool {
+	i, ok := g.index(p)
+	return ok && g.filled(i)
+}
+
+// Points returns every filled cube ordered by z then y then x
+func (g *Grid) Points() []Point {
+	points := make([]Point, 0, g.count)
+	for i := 0; i < g.cells(); i++ {
+		if g.filled(i) {
+			points = append(points, g.point(i))
+		}
+	}
+	return points
+}
+
+// Bounds returns the smallest and largest corner of the box holding every filled cube
+// An empty grid returns the zero point for both
+func (g *Grid) Bounds() (min, max Point) {
+	if g.count == 0 {
+		return Point{}, Point{}
+	}
+	return g.origin.Add(Point{1, 1, 1}), g.origin.Add(g.size).Add(Point{-2, -2, -2})
+}
+
+// cells returns the number of cells in the box
+func (g *Grid) cells() int {
+	return g.size.X * g.size.Y * g.size.Z
+}
+
+// index returns the index of the cell at p and false if p is outside the box
+func (g *Grid) index(p Point) (int, bool) {
+	x, y, z := p.X-g.origin.X, p.Y-g.origin.Y, p.Z-g.origin.Z
+	if x < 0 || y < 0 || z < 0 || x >= g.size.X || y >= g.size.Y || z >= g.size.Z {
+		return 0, false
+	}
+	return x + g.size.X*(y+g.size.Y*z), true
+}
+
+// point returns the point of the cell at index i
+func (g *Grid) point(i int) Point {
+	return Point{
+		g.origin.X + i%g.size.X,
+		g.origin.Y + i/g.size.X%g.size.Y,
+		g.origin.Z + i/(g.size.X*g.size.Y),
+	}
+}
+
+// filled returns true if the cell at index i is filled
+func (g *Grid) filled(i int) bool {
+	return g.bits[i/64]&(1<<(i%64)) != 0
+}
+
+// minInt returns the smaller value of a and b
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger value of a and b
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
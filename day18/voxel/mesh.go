@@ -0,0 +1,116 @@
+package voxel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Face is one exposed face of a filled cube
+// Normal is the offset from Cube to the empty cell the face looks out on
+type Face struct {
+	Cube   Point
+	Normal Point
+}
+
+// Faces returns every face of a filled cube that touches an empty cell
+// With exteriorOnly the faces inside air pockets are left out
+// Faces are ordered by their cube ordered by z then y then x then in the order of Neighbours
+func (g *Grid) Faces(exteriorOnly bool) []Face {
+	var outside []bool
+	if exteriorOnly {
+		outside = make([]bool, g.cells())
+		g.flood(0, outside)
+	}
+
+	var faces []Face
+	for i := 0; i < g.cells(); i++ {
+		if !g.filled(i) {
+			continue
+		}
+		p := g.point(i)
+		for _, d := range Neighbours {
+			// Filled cubes never touch the edge of the box so every neighbour is inside it
+			j, _ := g.index(p.Add(d))
+			if !g.filled(j) && (outside == nil || outside[j]) {
+				faces = append(faces, Face{p, d})
+			}
+		}
+	}
+	return faces
+}
+
+// Corners returns the four corners of the face ordered anticlockwise when looking at the face from outside the cube
+// The cube at x,y,z covers x to x+1 on each axis
+func (f Face) Corners() [4]Point {
+	units := [3]Point{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	// Find the axis the face looks along and whether it looks the positive way
+	axis, positive := 0, false
+	for a, value := range [3]int{f.Normal.X, f.Normal.Y, f.Normal.Z} {
+		if value != 0 {
+			axis, positive = a, value > 0
+		}
+	}
+
+	// The face lies on the far side of the cube along a positive normal
+	base := f.Cube
+	if positive {
+		base = base.Add(f.Normal)
+	}
+
+	// u then v turn anticlockwise around the normal. Swapping them turns the face around
+	u, v := units[(axis+1)%3], units[(axis+2)%3]
+	if !positive {
+		u, v = v, u
+	}
+	return [4]Point{base, base.Add(u), base.Add(u).Add(v), base.Add(v)}
+}
+
+// WriteOBJ writes the faces as a Wavefront OBJ mesh of square faces
+// Corners shared by faces are written once and numbered in the order they are first used so the output is always the same
+func WriteOBJ(w io.Writer, faces []Face) error {
+	index := map[Point]int{}
+	var vertices []Point
+	quads := make([][4]int, len(faces))
+	for i, f := range faces {
+		for j, corner := range f.Corners() {
+			if _, ok := index[corner]; !ok {
+				vertices = append(vertices, corner)
+				// OBJ numbers vertices from 1
+				index[corner] = len(vertices)
+			}
+			quads[i][j] = index[corner]
+		}
+	}
+
+	// Writes to bw keep the first error so it only needs checking once at the end
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d faces\n", len(faces))
+	for _, v := range vertices {
+		fmt.Fprintf(bw, "v %d %d %d\n", v.X, v.Y, v.Z)
+	}
+	for _, q := range quads {
+		fmt.Fprintf(bw, "f %d %d %d %d\n", q[0], q[1], q[2], q[3])
+	}
+	return bw.Flush()
+}
+
+// WriteSTL writes the faces as an ASCII STL mesh named name
+// STL only holds triangles so each face is split into two
+func WriteSTL(w io.Writer, name string, faces []Face) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "solid %s\n", name)
+	for _, f := range faces {
+		c := f.Corners()
+		for _, triangle := range [2][3]Point{{c[0], c[1], c[2]}, {c[0], c[2], c[3]}} {
+			fmt.Fprintf(bw, "facet normal %d %d %d\n  outer loop\n", f.Normal.X, f.Normal.Y, f.Normal.Z)
+			for _, v := range triangle {
+				fmt.Fprintf(bw, "    vertex %d %d %d\n", v.X, v.Y, v.Z)
+			}
+			fmt.Fprintf(bw, "  endloop\nendfacet\n")
+		}
+	}
+	fmt.Fprintf(bw, "endsolid %s\n", name)
+	return bw.Flush()
+}
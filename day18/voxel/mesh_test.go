@@ -0,0 +1,101 @@
+package voxel
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites the golden files from the current output instead of comparing against them
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// hollowCube returns a 3 by 3 by 3 cube with the centre cube left out
+func hollowCube() []Point {
+	var points []Point
+	for z := 0; z < 3; z++ {
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 3; x++ {
+				if (Point{x, y, z}) != (Point{1, 1, 1}) {
+					points = append(points, Point{x, y, z})
+				}
+			}
+		}
+	}
+	return points
+}
+
+var meshTests = []struct {
+	name         string
+	points       []Point
+	exteriorOnly bool
+}{
+	{"two-cubes", []Point{{1, 1, 1}, {2, 1, 1}}, false},
+	{"hollow-exterior", hollowCube(), true},
+	{"hollow-all", hollowCube(), false},
+}
+
+func TestFaces(t *testing.T) {
+	for _, tt := range meshTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGrid(tt.points)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := g.SurfaceArea()
+			if tt.exteriorOnly {
+				want = g.ExteriorSurface()
+			}
+			if got := len(g.Faces(tt.exteriorOnly)); got != want {
+				t.Errorf("got %d faces, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteMesh(t *testing.T) {
+	for _, tt := range meshTests {
+		g, err := NewGrid(tt.points)
+		if err != nil {
+			t.Fatal(err)
+		}
+		faces := g.Faces(tt.exteriorOnly)
+
+		t.Run(tt.name+".obj", func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteOBJ(&b, faces); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.name+".obj", b.Bytes())
+		})
+		t.Run(tt.name+".stl", func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteSTL(&b, tt.name, faces); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.name+".stl", b.Bytes())
+		})
+	}
+}
+
+// checkGolden compares got with the golden file name in testdata or rewrites it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s, run go test -update to see the difference", path)
+	}
+}
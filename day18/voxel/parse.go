@@ -0,0 +1,52 @@
+package voxel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parse reads one point per line written as "x,y,z"
+// Blank lines are skipped and any other line that isn't three whole numbers is an error
+func Parse(r io.Reader) ([]Point, error) {
+	var points []Point
+
+	fileScanner := bufio.NewScanner(r)
+	line := 0
+	for fileScanner.Scan() {
+		line++
+		text := strings.TrimSpace(fileScanner.Text())
+		if text == "" {
+			continue
+		}
+
+		parts := strings.Split(text, ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("line %d: expected x,y,z but found %q", line, text)
+		}
+
+		var values [3]int
+		for i, part := range parts {
+			value, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			values[i] = value
+		}
+
+		points = append(points, Point{values[0], values[1], values[2]})
+	}
+
+	return points, fileScanner.Err()
+}
+
+// ParseGrid reads points like Parse and returns them as a grid
+func ParseGrid(r io.Reader) (*Grid, error) {
+	points, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewGrid(points)
+}
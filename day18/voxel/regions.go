@@ -0,0 +1,113 @@
+package voxel
+
+import "sort"
+
+// Region is a group of cells connected through their faces that are all filled or all empty
+// Surface is the number of faces between a cell in the region and a cell that is not
+type Region struct {
+	Points  []Point
+	Surface int
+}
+
+// Volume returns the number of cells in the region
+func (r Region) Volume() int {
+	return len(r.Points)
+}
+
+// SurfaceArea returns the number of faces of filled cubes that don't touch another filled cube
+// This includes the faces inside any air pockets
+func (g *Grid) SurfaceArea() int {
+	surface := 0
+	for i := 0; i < g.cells(); i++ {
+		if g.filled(i) {
+			g.eachNeighbour(i, func(j int) {
+				if !g.filled(j) {
+					surface++
+				}
+			})
+		}
+	}
+	// Filled cubes never touch the edge of the box so every face has a neighbour inside it
+	return surface
+}
+
+// ExteriorSurface returns the number of faces of filled cubes that can be reached from outside the shape
+// This is the surface area without the faces inside air pockets
+func (g *Grid) ExteriorSurface() int {
+	// The corner of the box is always outside the shape as the box is grown by one cell on every side
+	return g.flood(0, make([]bool, g.cells())).Surface
+}
+
+// Pockets returns the regions of air completely enclosed by the shape
+// They are ordered by their first cell ordered by z then y then x
+func (g *Grid) Pockets() []Region {
+	seen := make([]bool, g.cells())
+	g.flood(0, seen)
+
+	var pockets []Region
+	for i := range seen {
+		if !seen[i] && !g.filled(i) {
+			pockets = append(pockets, g.flood(i, seen))
+		}
+	}
+	return pockets
+}
+
+// Components returns the separate pieces of the shape that don't share a face with each other
+// They are ordered by their first cell ordered by z then y then x
+func (g *Grid) Components() []Region {
+	seen := make([]bool, g.cells())
+
+	var components []Region
+	for i := range seen {
+		if !seen[i] && g.filled(i) {
+			components = append(components, g.flood(i, seen))
+		}
+	}
+	return components
+}
+
+// flood returns the region holding the cell at index start marking each of its cells in seen
+// The region grows into every neighbouring cell that is filled if start is filled or empty if start is empty
+// Each face reached between a cell of the region and a cell that is not is counted as surface
+// The points of the region are listed ordered by z then y then x
+func (g *Grid) flood(start int, seen []bool) Region {
+	solid := g.filled(start)
+
+	var region Region
+	var cells []int
+	queue := []int{start}
+	seen[start] = true
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		cells = append(cells, current)
+
+		g.eachNeighbour(current, func(next int) {
+			if g.filled(next) != solid {
+				region.Surface++
+			} else if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		})
+	}
+
+	// Cells are found in the order they are reached. Sort them by index so the points are always in the same order
+	sort.Ints(cells)
+	region.Points = make([]Point, len(cells))
+	for i, cell := range cells {
+		region.Points[i] = g.point(cell)
+	}
+	return region
+}
+
+// eachNeighbour calls f with the index of every cell sharing a face with the cell at index i inside the box
+func (g *Grid) eachNeighbour(i int, f func(j int)) {
+	p := g.point(i)
+	for _, d := range Neighbours {
+		if j, ok := g.index(p.Add(d)); ok {
+			f(j)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// resource is one of the materials robots collect
+// Each resource is an index into the fixed size arrays used for stock, robots and costs
+type resource int
+
+// Use constants for each of the building materials
+const (
+	ore resource = iota
+	clay
+	obsidian
+	geode
+	resourceCount
+)
+
+// resourceNames holds the name of each resource as written in the blueprints
+var resourceNames = [resourceCount]string{"ore", "clay", "obsidian", "geode"}
+
+func (r resource) String() string {
+	return resourceNames[r]
+}
+
+// blueprint represents a blueprint line from the input file
+// Each blueprint has a unique id to identify it
+// costs[r] holds the amount of each resource needed to build a robot collecting resource r
+// maxSpend[r] is the most of resource r any robot costs. Only one robot can be built each minute so more robots
+// collecting r than this would collect resources that can never be spent. Geodes are never spent so have no limit
+type blueprint struct {
+	id       int
+	costs    [resourceCount][resourceCount]int
+	maxSpend [resourceCount]int
+}
+
+// parseBlueprints reads one blueprint from each line of r
+func parseBlueprints(r io.Reader) ([]blueprint, error) {
+	list := []blueprint{}
+
+	fileScanner := bufio.NewScanner(r)
+	for line := 1; fileScanner.Scan(); line++ {
+		if fileScanner.Text() == "" {
+			continue
+		}
+
+		var bp blueprint
+		c := &bp.costs
+		_, err := fmt.Sscanf(fileScanner.Text(), "Blueprint %d: Each ore robot costs %d ore. Each clay robot costs %d ore. Each obsidian robot costs %d ore and %d clay. Each geode robot costs %d ore and %d obsidian.",
+			&bp.id, &c[ore][ore], &c[clay][ore], &c[obsidian][ore], &c[obsidian][clay], &c[geode][ore], &c[geode][obsidian])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid blueprint: %w", line, err)
+		}
+
+		for robot := ore; robot < resourceCount; robot++ {
+			for r := ore; r < resourceCount; r++ {
+				if c[robot][r] > bp.maxSpend[r] {
+					bp.maxSpend[r] = c[robot][r]
+				}
+			}
+		}
+
+		list = append(list, bp)
+	}
+	return list, fileScanner.Err()
+}
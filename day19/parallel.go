@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// result is the most geodes one blueprint can open and how long the search took
+type result struct {
+	id      int
+	geodes  int
+	elapsed time.Duration
+}
+
+// evaluate finds the most geodes each blueprint can open in minutes using a pool of workers
+// The results are in the same order as blueprints however the work was scheduled
+// If ctx is cancelled or any search fails the remaining searches are stopped and the error is returned
+func evaluate(ctx context.Context, blueprints []blueprint, minutes, workers int) ([]result, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	results := make([]result, len(blueprints))
+	errs := make([]error, len(blueprints))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				start := time.Now()
+				geodes, err := maxGeodes(ctx, &blueprints[i], minutes)
+				if err != nil {
+					errs[i] = err
+					cancel()
+					continue
+				}
+				results[i] = result{id: blueprints[i].id, geodes: geodes, elapsed: time.Since(start)}
+			}
+		}()
+	}
+
+	// Hand out each blueprint until every one is taken or the evaluation is cancelled
+send:
+	for i := range blueprints {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// writeTimings prints the geodes found for each blueprint and how long its search took
+func writeTimings(w io.Writer, results []result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "blueprint\tgeodes\ttime\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%d\t%v\t\n", r.id, r.geodes, r.elapsed)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,123 @@
+package main
+
+import "context"
+
+// search finds the most geodes a blueprint can open with a depth-first branch and bound
+// best is the most geodes found so far by any branch
+// done is checked before each branch so the search stops soon after its context is cancelled
+type search struct {
+	bp        *blueprint
+	best      int
+	done      <-chan struct{}
+	cancelled bool
+}
+
+// maxGeodes returns the largest number of geodes that can be opened in minutes using blueprint bp
+// Each simulation begins with 1 ore collecting robot as defined in the problem
+// The context's error is returned if ctx is cancelled before the search finishes
+func maxGeodes(ctx context.Context, bp *blueprint, minutes int) (int, error) {
+	s := &search{bp: bp, done: ctx.Done()}
+	s.dfs(minutes, [resourceCount]int{ore: 1}, [resourceCount]int{})
+	if s.cancelled {
+		return 0, ctx.Err()
+	}
+	return s.best, nil
+}
+
+// dfs explores every order of robots to build with timeLeft minutes to go holding robots and stock
+//
+// Rather than stepping one minute at a time each branch chooses the next robot to build and skips straight to the minute
+// it is finished, collecting resources along the way. Waiting without building anything again is covered by the branch
+// ending, as the geode robots keep collecting until time runs out
+// Branches are skipped when they build robots that can't help or when even an optimistic guess can't beat the best so far
+func (s *search) dfs(timeLeft int, robots, stock [resourceCount]int) {
+	select {
+	case <-s.done:
+		s.cancelled = true
+		return
+	default:
+	}
+
+	// The geodes opened if nothing else is built
+	if geodes := stock[geode] + robots[geode]*timeLeft; geodes > s.best {
+		s.best = geodes
+	}
+	if s.upperBound(timeLeft, robots, stock) <= s.best {
+		return
+	}
+
+	// Try the robots most likely to lead to geodes first so good answers are found early and prune more
+	for robot := geode; robot >= ore; robot-- {
+		if robot != geode {
+			// Only one robot can be built each minute so there is no point collecting more than can be spent in a minute
+			// The same holds when the stock is already enough to build the most expensive robot every minute until the end
+			if robots[robot] >= s.bp.maxSpend[robot] || stock[robot]+robots[robot]*timeLeft >= s.bp.maxSpend[robot]*timeLeft {
+				continue
+			}
+		}
+
+		// Find how many minutes it takes to collect enough of every resource the robot needs
+		wait, possible := 0, true
+		for r := ore; r < geode; r++ {
+			needed := s.bp.costs[robot][r] - stock[r]
+			if needed <= 0 {
+				continue
+			}
+			if robots[r] == 0 {
+				possible = false
+				break
+			}
+			if w := (needed + robots[r] - 1) / robots[r]; w > wait {
+				wait = w
+			}
+		}
+
+		// The robot takes a minute to build after waiting and must finish with time left to collect anything
+		remaining := timeLeft - wait - 1
+		if !possible || remaining <= 0 {
+			continue
+		}
+
+		next := stock
+		for r := ore; r < resourceCount; r++ {
+			next[r] += robots[r]*(wait+1) - s.bp.costs[robot][r]
+		}
+		built := robots
+		built[robot]++
+		s.dfs(remaining, built, next)
+		if s.cancelled {
+			return
+		}
+	}
+}
+
+// upperBound returns a number of geodes that can't be beaten with timeLeft minutes to go holding robots and stock
+// It relaxes the rules so that ore is free and a clay, obsidian and geode robot can all be built in the same minute
+// whenever there is enough clay or obsidian for them
+func (s *search) upperBound(timeLeft int, robots, stock [resourceCount]int) int {
+	clayRobots, obsidianRobots, geodeRobots := robots[clay], robots[obsidian], robots[geode]
+	clayStock, obsidianStock, geodes := stock[clay], stock[obsidian], stock[geode]
+
+	for t := 0; t < timeLeft; t++ {
+		buildObsidian := clayStock >= s.bp.costs[obsidian][clay]
+		buildGeode := obsidianStock >= s.bp.costs[geode][obsidian]
+
+		// Collect with the robots there were at the start of the minute
+		clayStock += clayRobots
+		obsidianStock += obsidianRobots
+		geodes += geodeRobots
+
+		// Then finish the robots built this minute
+		clayRobots++
+		if buildObsidian {
+			clayStock -= s.bp.costs[obsidian][clay]
+			obsidianRobots++
+		}
+		if buildGeode {
+			obsidianStock -= s.bp.costs[geode][obsidian]
+			geodeRobots++
+		}
+	}
+
+	return geodes
+}